@@ -0,0 +1,64 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+)
+
+var (
+	//go:embed DerivedAge.txt.gz
+	derivedAgeGz []byte
+	ages         []rangeValue
+)
+
+// age returns the version of Unicode in which r was assigned, such as "6.1",
+// or "" if it is unassigned.
+func age(r rune) string {
+	if ages == nil {
+		ages = parseRanges(ucdText("DerivedAge.txt", derivedAgeGz))
+	}
+	if f := lookupRange(ages, r); f != nil {
+		return f[0]
+	}
+	return ""
+}
+
+// version parses a Unicode version such as "6.1" into a number
+// that sorts correctly, here 601.
+func version(s string) int {
+	major, minor := s, "0"
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		major, minor = s[:i], s[i+1:]
+	}
+	ma, err1 := strconv.Atoi(major)
+	mi, err2 := strconv.Atoi(minor)
+	if err1 != nil || err2 != nil || mi >= 100 {
+		fatalf("bad Unicode version %q", s)
+	}
+	return ma*100 + mi
+}
+
+// ofAge returns the runes of codes whose age satisfies the comparison
+// expr, such as ">=15.0" or "<6"; a bare version requires equality.
+func ofAge(codes []rune, expr string) []rune {
+	i := strings.IndexAny(expr, "0123456789")
+	if i < 0 {
+		fatalf("bad age comparison %q", expr)
+	}
+	v, cmp := version(expr[i:]), cmpFunc(strings.TrimSpace(expr[:i]))
+	if cmp == nil {
+		fatalf("bad age comparison %q", expr)
+	}
+	var out []rune
+	for _, r := range codes {
+		if a := age(r); a != "" && cmp(compareInts(version(a), v)) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations gives ASCII for the letters and symbols that have no
+// compatibility decomposition to ASCII.
+var transliterations = map[rune]string{
+	'Æ': "AE", 'æ': "ae",
+	'Œ': "OE", 'œ': "oe",
+	'Ø': "O", 'ø': "o",
+	'Đ': "D", 'đ': "d",
+	'Ð': "D", 'ð': "d",
+	'Ħ': "H", 'ħ': "h",
+	'Ł': "L", 'ł': "l",
+	'Þ': "TH", 'þ': "th",
+	'ß': "ss", 'ẞ': "SS",
+	'ı': "i", 'ȷ': "j",
+	'Ŋ': "NG", 'ŋ': "ng",
+	'ĸ': "q",
+	'Ŧ': "T", 'ŧ': "t",
+	'‘': "'", '’': "'", '‚': "'", '‛': "'",
+	'“': "\"", '”': "\"", '„': "\"", '‟': "\"",
+	'‹': "<", '›': ">", '«': "<<", '»': ">>",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-", '−': "-",
+	'•': "*", '·': ".", '×': "x", '÷': "/",
+	'¡': "!", '¿': "?",
+	'©': "(C)", '®': "(R)", '°': "deg",
+	'¢': "c", '£': "GBP", '¥': "JPY", '€': "EUR",
+}
+
+// toASCII returns an ASCII approximation of r: its compatibility
+// decomposition without combining marks, with the remaining non-ASCII
+// characters transliterated, or replaced by ? if that is not possible.
+func toASCII(r rune) string {
+	var b strings.Builder
+	for _, c := range norm.NFKD.String(string(r)) {
+		switch {
+		case c < 0x80:
+			b.WriteRune(c)
+		case unicode.Is(unicode.Mn, c):
+		case transliterations[c] != "":
+			b.WriteString(transliterations[c])
+		case unicode.IsSpace(c):
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// ascii prints each argument, or each line of standard input if there are
+// none, converted to ASCII, followed by the characters that were changed.
+func ascii(args []string) {
+	for _, a := range argsOrStdin(args) {
+		var b strings.Builder
+		for _, r := range a {
+			b.WriteString(toASCII(r))
+		}
+		fmt.Printf("'%s' → '%s'\n", a, b.String())
+		printMappings(a, toASCII)
+	}
+}
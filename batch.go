@@ -0,0 +1,51 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"strings"
+)
+
+// batch returns the characters of the queries, one to a line, in the named
+// file, or in standard input if the name is "-": hex numbers and ranges in
+// the form of -c arguments, or g: followed by a regular expression matched
+// against the names as by -g. Blank lines and lines beginning with # are
+// skipped. The database is loaded once for all of them.
+func batch(file string) []rune {
+	if len(flag.Args()) > 0 {
+		fatalf("-batch takes no arguments")
+	}
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		fatalf("%s", err)
+	}
+	var codes []rune
+	for i, line := range splitLines(string(data)) {
+		q := strings.TrimSpace(line)
+		switch {
+		case q == "" || strings.HasPrefix(q, "#"):
+		case strings.HasPrefix(q, "g:"):
+			found := matching(q[2:])
+			if len(found) == 0 {
+				failStrict(exitNotFound, "%s:%d: nothing matches %s", file, i+1, q[2:])
+			}
+			codes = append(codes, found...)
+		case isCodeRanges(q):
+			codes = append(codes, codeRangesOf(q)...)
+		default:
+			fatalf("%s:%d: want hex code points or ranges, or g:regexp, not %q", file, i+1, q)
+		}
+	}
+	return codes
+}
@@ -0,0 +1,642 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// This file implements the Unicode Bidirectional Algorithm of UAX #9 for
+// a single line of text, to show how it resolves the embedding level of each
+// rune. The rules are named as in the standard. The bidi classes are those
+// of UnicodeData.txt, the bracket pairs those of BidiBrackets.txt and the
+// mirrored glyphs those of BidiMirroring.txt.
+
+var (
+	//go:embed BidiBrackets.txt.gz
+	bidiBracketsGz []byte
+	bidiBrackets   map[rune]bracket
+	//go:embed BidiMirroring.txt.gz
+	bidiMirroringGz []byte
+	bidiMirrors     []rangeValue
+)
+
+// A bracket is an entry of BidiBrackets.txt.
+type bracket struct {
+	pair    rune // The Bidi_Paired_Bracket.
+	opening bool
+}
+
+func loadBidiBrackets() {
+	if bidiBrackets != nil {
+		return
+	}
+	bidiBrackets = make(map[rune]bracket)
+	for _, v := range parseRanges(ucdText("BidiBrackets.txt", bidiBracketsGz)) {
+		bidiBrackets[v.lo] = bracket{parseRune(v.fields[0]), v.fields[1] == "o"}
+	}
+}
+
+// bidiMirror returns the Bidi_Mirroring_Glyph of r: the character whose
+// glyph is the mirror image of that of r, if there is one.
+func bidiMirror(r rune) (rune, bool) {
+	if bidiMirrors == nil {
+		bidiMirrors = parseRanges(ucdText("BidiMirroring.txt", bidiMirroringGz))
+	}
+	if f := lookupRange(bidiMirrors, r); f != nil {
+		return parseRune(f[0]), true
+	}
+	return 0, false
+}
+
+// bidiClass returns the Bidi_Class of r. Unassigned code points are L,
+// which is their default outside the right-to-left blocks.
+func bidiClass(r rune) string {
+	fields := strings.Split(lookup(r), ";")
+	if len(fields) < 4 || fields[3] == "" {
+		return "L"
+	}
+	return fields[3]
+}
+
+const maxDepth = 125 // The maximum explicit embedding level.
+
+// A bidiPara holds the state of the algorithm for a line of text.
+type bidiPara struct {
+	runes   []rune
+	initial []string // The original bidi classes.
+	class   []string // The classes as they are resolved.
+	level   []int
+	removed []bool // Whether rule X9 removed the rune.
+	para    int    // The paragraph embedding level.
+	matchPD []int  // For isolate initiators, the index of the matching PDI, or -1.
+}
+
+func isIsolateInit(c string) bool {
+	return c == "LRI" || c == "RLI" || c == "FSI"
+}
+
+// isNI reports whether c is a neutral or isolate formatting character.
+func isNI(c string) bool {
+	switch c {
+	case "B", "S", "WS", "ON", "LRI", "RLI", "FSI", "PDI":
+		return true
+	}
+	return false
+}
+
+func direction(level int) string {
+	if level%2 == 0 {
+		return "L"
+	}
+	return "R"
+}
+
+// newBidiPara prepares s for the algorithm and resolves it. The paragraph
+// level is determined by rules P2 and P3.
+func newBidiPara(s string) *bidiPara {
+	loadBidiBrackets()
+	p := &bidiPara{runes: []rune(s)}
+	n := len(p.runes)
+	p.initial = make([]string, n)
+	p.class = make([]string, n)
+	p.level = make([]int, n)
+	p.removed = make([]bool, n)
+	for i, r := range p.runes {
+		p.initial[i] = bidiClass(r)
+		p.class[i] = p.initial[i]
+	}
+	p.matchPDIs()
+	p.para = p.firstStrong(0, n, 0)
+	p.explicit()
+	for _, seq := range p.isolatingRunSequences() {
+		p.resolveWeak(seq)
+		p.resolveBrackets(seq)
+		p.resolveNeutral(seq)
+		p.resolveImplicit(seq)
+	}
+	p.resetWhitespace()
+	return p
+}
+
+// matchPDIs records the PDI that matches each isolate initiator (BD9).
+func (p *bidiPara) matchPDIs() {
+	p.matchPD = make([]int, len(p.runes))
+	var stack []int
+	for i, c := range p.initial {
+		p.matchPD[i] = -1
+		switch {
+		case isIsolateInit(c):
+			stack = append(stack, i)
+		case c == "PDI" && len(stack) > 0:
+			p.matchPD[stack[len(stack)-1]] = i
+			stack = stack[:len(stack)-1]
+		case c == "B":
+			stack = nil
+		}
+	}
+}
+
+// firstStrong returns 0 or 1 according to the first strong character in
+// p.runes[start:end], skipping isolates (P2, P3), or def if there is none.
+func (p *bidiPara) firstStrong(start, end, def int) int {
+	for i := start; i < end; i++ {
+		switch c := p.initial[i]; {
+		case c == "L":
+			return 0
+		case c == "R" || c == "AL":
+			return 1
+		case isIsolateInit(c):
+			if p.matchPD[i] < 0 {
+				return def
+			}
+			i = p.matchPD[i]
+		}
+	}
+	return def
+}
+
+// explicit applies the explicit embedding rules X1 to X9.
+func (p *bidiPara) explicit() {
+	type status struct {
+		level    int
+		override string // "", "L" or "R".
+		isolate  bool
+	}
+	stack := []status{{p.para, "", false}}
+	overflowIsolates, overflowEmbeddings, validIsolates := 0, 0, 0
+	// next returns the least odd or even level greater than the current one.
+	next := func(odd bool) int {
+		l := stack[len(stack)-1].level + 1
+		if (l%2 == 1) != odd {
+			l++
+		}
+		return l
+	}
+	for i, c := range p.initial {
+		top := stack[len(stack)-1]
+		switch c {
+		case "RLE", "LRE", "RLO", "LRO": // X2 to X5.
+			l := next(c[0] == 'R')
+			p.level[i] = top.level
+			p.removed[i] = true
+			if l <= maxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				s := status{level: l}
+				if c[2] == 'O' {
+					s.override = c[:1]
+				}
+				stack = append(stack, s)
+			} else if overflowIsolates == 0 {
+				overflowEmbeddings++
+			}
+		case "RLI", "LRI", "FSI": // X5a to X5c.
+			p.level[i] = top.level
+			if top.override != "" {
+				p.class[i] = top.override
+			}
+			rtl := c == "RLI"
+			if c == "FSI" {
+				end := p.matchPD[i]
+				if end < 0 {
+					end = len(p.runes)
+				}
+				rtl = p.firstStrong(i+1, end, 0) == 1
+			}
+			l := next(rtl)
+			if l <= maxDepth && overflowIsolates == 0 && overflowEmbeddings == 0 {
+				validIsolates++
+				stack = append(stack, status{level: l, isolate: true})
+			} else {
+				overflowIsolates++
+			}
+		case "PDI": // X6a.
+			switch {
+			case overflowIsolates > 0:
+				overflowIsolates--
+			case validIsolates > 0:
+				overflowEmbeddings = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIsolates--
+			}
+			top = stack[len(stack)-1]
+			p.level[i] = top.level
+			if top.override != "" {
+				p.class[i] = top.override
+			}
+		case "PDF": // X7.
+			p.level[i] = top.level
+			p.removed[i] = true
+			switch {
+			case overflowIsolates > 0:
+			case overflowEmbeddings > 0:
+				overflowEmbeddings--
+			case !top.isolate && len(stack) >= 2:
+				stack = stack[:len(stack)-1]
+			}
+		case "B": // X8.
+			p.level[i] = p.para
+		case "BN": // X9.
+			p.level[i] = top.level
+			p.removed[i] = true
+		default: // X6.
+			p.level[i] = top.level
+			if top.override != "" {
+				p.class[i] = top.override
+			}
+		}
+	}
+}
+
+// An isolating run sequence is a list of indexes of runes, with the
+// classes of the start and end of the sequence (X10).
+type runSequence struct {
+	index    []int
+	sos, eos string
+}
+
+// isolatingRunSequences returns the isolating run sequences of p (BD13).
+func (p *bidiPara) isolatingRunSequences() []*runSequence {
+	// Split the runes that were not removed into level runs.
+	var runs [][]int
+	var run []int
+	for i := range p.runes {
+		if p.removed[i] {
+			continue
+		}
+		if len(run) > 0 && p.level[run[0]] != p.level[i] {
+			runs = append(runs, run)
+			run = nil
+		}
+		run = append(run, i)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	// Chain the runs that end with an isolate initiator to the runs that
+	// start with its matching PDI.
+	startsAt := make(map[int]int)
+	for j, run := range runs {
+		startsAt[run[0]] = j
+	}
+	used := make([]bool, len(runs))
+	var seqs []*runSequence
+	for j := range runs {
+		if used[j] {
+			continue
+		}
+		seq := new(runSequence)
+		for k := j; ; {
+			used[k] = true
+			seq.index = append(seq.index, runs[k]...)
+			last := runs[k][len(runs[k])-1]
+			m := p.matchPD[last]
+			if !isIsolateInit(p.initial[last]) || m < 0 {
+				break
+			}
+			next, ok := startsAt[m]
+			if !ok {
+				break
+			}
+			k = next
+		}
+		first, last := seq.index[0], seq.index[len(seq.index)-1]
+		level := p.level[first]
+		before, after := p.para, p.para
+		for i := first - 1; i >= 0; i-- {
+			if !p.removed[i] {
+				before = p.level[i]
+				break
+			}
+		}
+		if !isIsolateInit(p.initial[last]) || p.matchPD[last] < 0 {
+			for i := last + 1; i < len(p.runes); i++ {
+				if !p.removed[i] {
+					after = p.level[i]
+					break
+				}
+			}
+		}
+		seq.sos = direction(max(level, before))
+		seq.eos = direction(max(level, after))
+		seqs = append(seqs, seq)
+	}
+	return seqs
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// resolveWeak applies the weak type rules W1 to W7.
+func (p *bidiPara) resolveWeak(seq *runSequence) {
+	cls := p.class
+	idx := seq.index
+	// W1.
+	prev := seq.sos
+	for _, i := range idx {
+		if cls[i] == "NSM" {
+			if isIsolateInit(prev) || prev == "PDI" {
+				cls[i] = "ON"
+			} else {
+				cls[i] = prev
+			}
+		}
+		prev = cls[i]
+	}
+	// W2 and W3.
+	strong := seq.sos
+	for _, i := range idx {
+		switch cls[i] {
+		case "L", "R", "AL":
+			strong = cls[i]
+		case "EN":
+			if strong == "AL" {
+				cls[i] = "AN"
+			}
+		}
+	}
+	for _, i := range idx {
+		if cls[i] == "AL" {
+			cls[i] = "R"
+		}
+	}
+	// W4.
+	for k := 1; k+1 < len(idx); k++ {
+		a, c, b := cls[idx[k-1]], cls[idx[k]], cls[idx[k+1]]
+		switch {
+		case c == "ES" && a == "EN" && b == "EN":
+			cls[idx[k]] = "EN"
+		case c == "CS" && a == b && (a == "EN" || a == "AN"):
+			cls[idx[k]] = a
+		}
+	}
+	// W5.
+	for k := 0; k < len(idx); k++ {
+		if cls[idx[k]] != "ET" {
+			continue
+		}
+		end := k
+		for end < len(idx) && cls[idx[end]] == "ET" {
+			end++
+		}
+		if k > 0 && cls[idx[k-1]] == "EN" || end < len(idx) && cls[idx[end]] == "EN" {
+			for ; k < end; k++ {
+				cls[idx[k]] = "EN"
+			}
+		}
+		k = end - 1
+	}
+	// W6.
+	for _, i := range idx {
+		switch cls[i] {
+		case "ES", "ET", "CS":
+			cls[i] = "ON"
+		}
+	}
+	// W7.
+	strong = seq.sos
+	for _, i := range idx {
+		switch cls[i] {
+		case "L", "R":
+			strong = cls[i]
+		case "EN":
+			if strong == "L" {
+				cls[i] = "L"
+			}
+		}
+	}
+}
+
+// strongDir returns the direction of c for the neutral rules, in which
+// numbers count as R, or "" if c has none.
+func strongDir(c string) string {
+	switch c {
+	case "L":
+		return "L"
+	case "R", "EN", "AN":
+		return "R"
+	}
+	return ""
+}
+
+// resolveBrackets applies rule N0 to the bracket pairs of the sequence.
+func (p *bidiPara) resolveBrackets(seq *runSequence) {
+	idx := seq.index
+	// canonical maps the angle brackets to their canonical equivalents.
+	canonical := func(r rune) rune {
+		switch r {
+		case 0x2329:
+			return 0x3008
+		case 0x232A:
+			return 0x3009
+		}
+		return r
+	}
+	// Identify the bracket pairs (BD16), as positions in idx.
+	type pair struct{ open, close int }
+	var pairs []pair
+	var stack []int
+outer:
+	for k, i := range idx {
+		b, ok := bidiBrackets[p.runes[i]]
+		if !ok || p.class[i] != "ON" {
+			continue
+		}
+		if b.opening {
+			if len(stack) == 63 {
+				break
+			}
+			stack = append(stack, k)
+			continue
+		}
+		for s := len(stack) - 1; s >= 0; s-- {
+			if canonical(bidiBrackets[p.runes[idx[stack[s]]]].pair) == canonical(p.runes[i]) {
+				pairs = append(pairs, pair{stack[s], k})
+				stack = stack[:s]
+				continue outer
+			}
+		}
+	}
+	// Sort the pairs by the position of the opening bracket.
+	for a := 1; a < len(pairs); a++ {
+		for b := a; b > 0 && pairs[b].open < pairs[b-1].open; b-- {
+			pairs[b], pairs[b-1] = pairs[b-1], pairs[b]
+		}
+	}
+	embedding := direction(p.level[idx[0]])
+	for _, pr := range pairs {
+		found := ""
+		for k := pr.open + 1; k < pr.close; k++ {
+			d := strongDir(p.class[idx[k]])
+			if d == embedding {
+				found = d
+				break
+			}
+			if d != "" {
+				found = d
+			}
+		}
+		if found == "" {
+			continue
+		}
+		if found != embedding {
+			// N0 c: use the opposite direction if the context before the
+			// opening bracket also has it.
+			context := seq.sos
+			for k := pr.open - 1; k >= 0; k-- {
+				if d := strongDir(p.class[idx[k]]); d != "" {
+					context = d
+					break
+				}
+			}
+			if context != found {
+				found = embedding
+			}
+		}
+		for _, k := range []int{pr.open, pr.close} {
+			p.class[idx[k]] = found
+			// Marks that followed the bracket take its new class.
+			for k++; k < len(idx) && p.initial[idx[k]] == "NSM"; k++ {
+				p.class[idx[k]] = found
+			}
+		}
+	}
+}
+
+// resolveNeutral applies rules N1 and N2.
+func (p *bidiPara) resolveNeutral(seq *runSequence) {
+	idx := seq.index
+	embedding := direction(p.level[idx[0]])
+	for k := 0; k < len(idx); k++ {
+		if !isNI(p.class[idx[k]]) {
+			continue
+		}
+		end := k
+		for end < len(idx) && isNI(p.class[idx[end]]) {
+			end++
+		}
+		before, after := seq.sos, seq.eos
+		if k > 0 {
+			before = strongDir(p.class[idx[k-1]])
+		}
+		if end < len(idx) {
+			after = strongDir(p.class[idx[end]])
+		}
+		d := embedding
+		if before != "" && before == after {
+			d = before
+		}
+		for ; k < end; k++ {
+			p.class[idx[k]] = d
+		}
+		k = end - 1
+	}
+}
+
+// resolveImplicit applies rules I1 and I2.
+func (p *bidiPara) resolveImplicit(seq *runSequence) {
+	for _, i := range seq.index {
+		c := p.class[i]
+		if p.level[i]%2 == 0 {
+			switch c {
+			case "R":
+				p.level[i]++
+			case "AN", "EN":
+				p.level[i] += 2
+			}
+		} else if c == "L" || c == "EN" || c == "AN" {
+			p.level[i]++
+		}
+	}
+}
+
+// resetWhitespace applies rule L1, which resets separators, and whitespace
+// before them or at the end of the line, to the paragraph level.
+func (p *bidiPara) resetWhitespace() {
+	trailing := true
+	for i := len(p.runes) - 1; i >= 0; i-- {
+		switch c := p.initial[i]; {
+		case c == "S" || c == "B":
+			p.level[i] = p.para
+			trailing = true
+		case c == "WS" || isIsolateInit(c) || c == "PDI" || p.removed[i]:
+			if trailing {
+				p.level[i] = p.para
+			}
+		default:
+			trailing = false
+		}
+	}
+}
+
+// visualOrder returns the indexes of the runes in display order (L2).
+// Runes removed by X9 are omitted.
+func (p *bidiPara) visualOrder() []int {
+	order := make([]int, 0, len(p.runes))
+	highest, lowestOdd := 0, maxDepth+2
+	for i, l := range p.level {
+		if p.removed[i] {
+			continue
+		}
+		order = append(order, i)
+		highest = max(highest, l)
+		if l%2 == 1 && l < lowestOdd {
+			lowestOdd = l
+		}
+	}
+	for l := highest; l >= lowestOdd; l-- {
+		for k := 0; k < len(order); k++ {
+			if p.level[order[k]] < l {
+				continue
+			}
+			end := k
+			for end < len(order) && p.level[order[end]] >= l {
+				end++
+			}
+			for a, b := k, end-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			k = end
+		}
+	}
+	return order
+}
+
+// bidi prints, for each argument or each line of standard input if there
+// are none, the paragraph level, then the bidi class and resolved embedding
+// level of each rune, and finally the text in display order, in which the
+// characters at right-to-left levels are replaced by their mirrors (L4).
+func bidi(args []string) {
+	for _, a := range argsOrStdin(args) {
+		p := newBidiPara(a)
+		fmt.Printf("'%s'\tparagraph level %d\n", a, p.para)
+		for i, r := range p.runes {
+			level := fmt.Sprint(p.level[i])
+			if p.removed[i] {
+				level = "x"
+			}
+			fmt.Printf("\t%U\t%s\t%s\t%s\n", r, p.initial[i], level, ucd.Name(r))
+		}
+		var b strings.Builder
+		for _, i := range p.visualOrder() {
+			r := p.runes[i]
+			if m, ok := bidiMirror(r); ok && p.level[i]%2 == 1 {
+				r = m
+			}
+			b.WriteRune(r)
+		}
+		fmt.Printf("\tdisplay: '%s'\n", b.String())
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+var (
+	//go:embed Blocks.txt.gz
+	blocksGz []byte
+	blocks   []rangeValue
+)
+
+func loadBlocks() {
+	if blocks == nil {
+		blocks = parseRanges(ucdText("Blocks.txt", blocksGz))
+	}
+}
+
+// block returns the name of the block containing r, or "No_Block".
+func block(r rune) string {
+	loadBlocks()
+	if f := lookupRange(blocks, r); f != nil {
+		return f[0]
+	}
+	return "No_Block"
+}
+
+// lookupBlock returns the range of the named block, matched loosely.
+func lookupBlock(name string) rangeValue {
+	loadBlocks()
+	for _, b := range blocks {
+		if looseName(b.fields[0]) == looseName(name) {
+			return b
+		}
+	}
+	fatalf("unknown block %q", name)
+	return rangeValue{}
+}
+
+// parseCodeRange parses a code point such as 2190 or a range of them such
+// as 2190-2BFF. A range may be open at either end: 2600- runs to the last
+// assigned character and -7F starts at 0.
+func parseCodeRange(s string) (lo, hi rune) {
+	f := strings.Split(s, "-")
+	switch {
+	case len(f) == 1 && f[0] != "":
+		lo = parseRune(f[0])
+		return lo, lo
+	case len(f) != 2 || f[0] == "" && f[1] == "":
+		fatalf("bad range %q; want lo-hi, such as 2190-2BFF, lo- or -hi", s)
+	}
+	hi = lastAssigned()
+	if f[0] != "" {
+		lo = parseRune(f[0])
+	}
+	if f[1] != "" {
+		hi = parseRune(f[1])
+	}
+	if hi < lo {
+		fatalf("bad range %q: %04X comes after %04X", s, lo, hi)
+	}
+	return lo, hi
+}
+
+// parseCodeRanges parses a comma-separated list of code points and ranges
+// such as 0-1F,7F-9F.
+func parseCodeRanges(s string) []rangeValue {
+	var list []rangeValue
+	for _, f := range strings.Split(s, ",") {
+		lo, hi := parseCodeRange(f)
+		list = append(list, rangeValue{lo: lo, hi: hi})
+	}
+	return list
+}
+
+// isCodeRanges reports whether s looks like a list for parseCodeRanges: hex
+// numbers, each alone or with at most one dash, separated by commas.
+func isCodeRanges(s string) bool {
+	for _, f := range strings.Split(s, ",") {
+		if f == "" || f == "-" || strings.Count(f, "-") > 1 {
+			return false
+		}
+		for _, c := range f {
+			if !strings.ContainsRune("0123456789abcdefABCDEF-", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lastAssigned returns the last code point in the database that is not
+// for private use.
+func lastAssigned() rune {
+	last := rune(0)
+	ucd.Each(func(e ucd.Entry) {
+		if e.Category != "Co" {
+			last = e.Code
+		}
+	})
+	return last
+}
+
+// inRanges returns the runes of codes that are in any of the ranges of
+// code points in the comma-separated list ranges, or, if byBlock is set,
+// in any of the blocks named there.
+func inRanges(codes []rune, ranges string, byBlock bool) []rune {
+	var list []rangeValue
+	for _, s := range strings.Split(ranges, ",") {
+		if byBlock {
+			list = append(list, lookupBlock(s))
+			continue
+		}
+		list = append(list, parseCodeRanges(s)...)
+	}
+	var out []rune
+	for _, r := range codes {
+		for _, v := range list {
+			if v.lo <= r && r <= v.hi {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// outputByBlock prints codes, grouped by block in the order in which the
+// blocks first appear, with a heading for each block.
+func outputByBlock(codes []rune) {
+	*doByBlock = false
+	var names []string
+	groups := make(map[string][]rune)
+	for _, r := range codes {
+		b := block(r)
+		if groups[b] == nil {
+			names = append(names, b)
+		}
+		groups[b] = append(groups[b], r)
+	}
+	for i, b := range names {
+		if i > 0 {
+			fmt.Println()
+		}
+		if b == "No_Block" {
+			fmt.Printf("%s:\n", b)
+		} else {
+			blk := lookupBlock(b)
+			fmt.Printf("%s (%04X..%04X):\n", b, blk.lo, blk.hi)
+		}
+		output(groups[b])
+	}
+}
+
+// planeNames holds the names of the planes of Unicode; the unnamed ones are
+// unassigned.
+var planeNames = [17]string{
+	0:  "Basic Multilingual Plane (BMP)",
+	1:  "Supplementary Multilingual Plane (SMP)",
+	2:  "Supplementary Ideographic Plane (SIP)",
+	3:  "Tertiary Ideographic Plane (TIP)",
+	14: "Supplementary Special-purpose Plane (SSP)",
+	15: "Supplementary Private Use Area-A (SPUA-A)",
+	16: "Supplementary Private Use Area-B (SPUA-B)",
+}
+
+// planeRoadmaps names the pages of the Unicode roadmaps for the planes
+// that have them, which place the scripts and symbols proposed for
+// encoding in the unallocated areas of each plane, tentatively.
+var planeRoadmaps = [17]string{0: "bmp", 1: "smp", 2: "sip", 3: "tip", 14: "ssp"}
+
+// describePlane returns the number and name of the plane of r.
+func describePlane(r rune) string {
+	name := planeNames[r>>16]
+	if name == "" {
+		name = "unassigned"
+	}
+	return fmt.Sprintf("%d, %s", r>>16, name)
+}
+
+// describeAllocation returns the block of r and its range or, if r is in
+// no block, the unallocated area around it in its plane and the blocks on
+// either side, with the status of the area on the Unicode roadmaps.
+func describeAllocation(r rune) (area, roadmap string) {
+	loadBlocks()
+	i := sort.Search(len(blocks), func(i int) bool { return blocks[i].hi >= r })
+	if i < len(blocks) && blocks[i].lo <= r {
+		b := blocks[i]
+		return fmt.Sprintf("%s, %04X..%04X", b.fields[0], b.lo, b.hi), ""
+	}
+	p := r >> 16
+	lo, hi := p<<16, p<<16|0xFFFF
+	var prev, next string
+	if i > 0 && blocks[i-1].hi >= lo {
+		lo, prev = blocks[i-1].hi+1, blocks[i-1].fields[0]
+	}
+	if i < len(blocks) && blocks[i].lo <= hi {
+		hi, next = blocks[i].lo-1, blocks[i].fields[0]
+	}
+	area = fmt.Sprintf("none; unallocated area %04X..%04X", lo, hi)
+	switch {
+	case prev != "" && next != "":
+		area += fmt.Sprintf(", between %s and %s", prev, next)
+	case prev != "":
+		area += fmt.Sprintf(", after %s", prev)
+	case next != "":
+		area += fmt.Sprintf(", before %s", next)
+	default:
+		area += ", the whole plane"
+	}
+	if planeRoadmaps[p] == "" {
+		return area, "no allocation is planned in this plane"
+	}
+	return area, fmt.Sprintf("unallocated; what is proposed for it, if anything, is placed tentatively on the roadmap at https://www.unicode.org/roadmaps/%s/", planeRoadmaps[p])
+}
+
+// catalog prints the blocks, the planes, or, if both are set, the blocks
+// under the heading of each plane, with their ranges and the number of
+// characters assigned in each.
+func catalog(showBlocks, showPlanes bool) {
+	loadBlocks()
+	var inPlane [17]int
+	inBlock := make(map[string]int)
+	ucd.Each(func(e ucd.Entry) {
+		inPlane[e.Code>>16]++
+		inBlock[block(e.Code)]++
+	})
+	for p := rune(0); p < 17; p++ {
+		lo, hi := p<<16, p<<16|0xFFFF
+		if showPlanes {
+			name := planeNames[p]
+			if name == "" {
+				name = "(unassigned)"
+			}
+			if showBlocks && p > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%-2d %04X..%04X %s: %d assigned\n", p, lo, hi, name, inPlane[p])
+		}
+		if !showBlocks {
+			continue
+		}
+		for _, b := range blocks {
+			if lo <= b.lo && b.lo <= hi {
+				indent := ""
+				if showPlanes {
+					indent = "\t"
+				}
+				fmt.Printf("%s%04X..%04X %s: %d of %d assigned\n", indent, b.lo, b.hi, b.fields[0], inBlock[b.fields[0]], b.hi-b.lo+1)
+			}
+		}
+	}
+}
+
+// listed returns the runes of codes that are assigned and in the block
+// named by spec, or in the script if spec is script:name, and selects -d.
+func listed(codes []rune, spec string) []rune {
+	describeByDefault()
+	if name := strings.TrimPrefix(spec, "script:"); name != spec {
+		codes = inScriptsOnly(codes, name)
+	} else {
+		codes = inRanges(codes, strings.TrimPrefix(spec, "block:"), true)
+	}
+	var out []rune
+	for _, r := range codes {
+		if ucd.Lookup(r).Assigned() {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+var (
+	//go:embed CaseFolding.txt.gz
+	caseFoldingGz []byte
+	caseFolds     map[rune][]caseFold
+)
+
+// A caseFold is an entry of CaseFolding.txt.
+type caseFold struct {
+	status  byte // C (common), F (full), S (simple) or T (Turkic).
+	mapping string
+}
+
+func (f caseFold) String() string {
+	s := codePoints(f.mapping)
+	switch f.status {
+	case 'F':
+		s += " (full)"
+	case 'S':
+		s += " (simple)"
+	case 'T':
+		s += " (Turkic)"
+	}
+	return s
+}
+
+func loadCaseFolding() {
+	if caseFolds != nil {
+		return
+	}
+	caseFolds = make(map[rune][]caseFold)
+	for i, line := range splitLines(ucdText("CaseFolding.txt", caseFoldingGz)) {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 3 || len(strings.TrimSpace(fields[1])) != 1 {
+			fatalf("malformed CaseFolding.txt: line %d", i+1)
+		}
+		var codes []rune
+		for _, f := range strings.Fields(fields[2]) {
+			codes = append(codes, parseRune(f))
+		}
+		r := parseRune(fields[0])
+		caseFolds[r] = append(caseFolds[r], caseFold{strings.TrimSpace(fields[1])[0], string(codes)})
+	}
+}
+
+// caseFolding returns the case folding entries for r.
+func caseFolding(r rune) []caseFold {
+	loadCaseFolding()
+	return caseFolds[r]
+}
+
+// fullFold returns the full case folding of r, which may be several runes.
+func fullFold(r rune) string {
+	for _, f := range caseFolding(r) {
+		if f.status == 'C' || f.status == 'F' {
+			return f.mapping
+		}
+	}
+	return string(r)
+}
+
+// fold prints the full case folding of each argument, or of each line of
+// standard input if there are none, followed by the mapping of each rune
+// that folding changed.
+func fold(args []string) {
+	for _, a := range argsOrStdin(args) {
+		var b strings.Builder
+		for _, r := range a {
+			b.WriteString(fullFold(r))
+		}
+		fmt.Printf("'%s' → '%s'\n", a, b.String())
+		printMappings(a, fullFold)
+	}
+}
+
+// printMappings prints each rune of s that fn changes, with its mapping.
+// The function is called for the runes of s in order.
+func printMappings(s string, fn func(rune) string) {
+	seen := make(map[string]bool)
+	for _, r := range s {
+		m := fn(r)
+		if m == string(r) || seen[string(r)+m] {
+			continue
+		}
+		seen[string(r)+m] = true
+		fmt.Printf("\t%U '%c' → %s '%s'\n", r, r, codePoints(m), m)
+	}
+}
+
+func joinCaseFolds(list []caseFold) string {
+	s := make([]string, len(list))
+	for i, f := range list {
+		s[i] = f.String()
+	}
+	return strings.Join(s, ", ")
+}
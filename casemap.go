@@ -0,0 +1,96 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// The case mapping fields of a line of the database, minus the code point.
+const (
+	upperField = 11
+	lowerField = 12
+	titleField = 13
+)
+
+// caseMapping returns the full case mapping of r of the given kind,
+// "lower", "title" or "upper": the unconditional mapping of
+// SpecialCasing.txt if there is one, and otherwise the simple mapping
+// of UnicodeData.txt.
+func caseMapping(r rune, kind string) string {
+	for _, c := range specialCasing(r) {
+		if c.condition == "" {
+			switch kind {
+			case "lower":
+				return c.lower
+			case "title":
+				return c.title
+			}
+			return c.upper
+		}
+	}
+	field := map[string]int{"lower": lowerField, "title": titleField, "upper": upperField}[kind]
+	fields := strings.Split(lookup(r), ";")
+	if len(fields) <= field || fields[field] == "" {
+		return string(r)
+	}
+	return string(parseRune(fields[field]))
+}
+
+// convertCase prints each argument, or each line of standard input if
+// there are none, converted to the given case, followed by the mapping
+// of each rune that the conversion changed and the conditional mappings
+// that may apply in context, such as the final form of sigma.
+func convertCase(kind string, args []string) {
+	var caser cases.Caser
+	switch kind {
+	case "lower":
+		caser = cases.Lower(language.Und)
+	case "title":
+		caser = cases.Title(language.Und)
+	default:
+		caser = cases.Upper(language.Und)
+	}
+	for _, a := range argsOrStdin(args) {
+		fmt.Printf("'%s' → '%s'\n", a, caser.String(a))
+		var fn func(rune) string
+		if kind == "title" {
+			// Title case the first cased letter of each word and lower case the rest.
+			inWord := false
+			fn = func(r rune) string {
+				k := "title"
+				if inWord {
+					k = "lower"
+				}
+				inWord = isCased(r) || inWord && isCaseIgnorable(r)
+				return caseMapping(r, k)
+			}
+		} else {
+			fn = func(r rune) string { return caseMapping(r, kind) }
+		}
+		printMappings(a, fn)
+		seen := make(map[rune]bool)
+		for _, r := range a {
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			for _, c := range specialCasing(r) {
+				// Language-specific conditions begin with a language tag such as tr.
+				if c.condition == "" || strings.ToLower(c.condition[:2]) == c.condition[:2] {
+					continue
+				}
+				m := map[string]string{"lower": c.lower, "title": c.title, "upper": c.upper}[kind]
+				if m != string(r) {
+					fmt.Printf("\t%U '%c' → %s '%s' when %s\n", r, r, codePoints(m), m, c.condition)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// chartStyle is the style sheet of a code chart.
+const chartStyle = `
+body { font-family: sans-serif; margin: 2em; }
+h1 { font-size: 150%; margin-bottom: 0; }
+table.grid { border-collapse: collapse; margin: 1em 0 2em; }
+table.grid th { font-weight: normal; font-family: monospace; padding: 0 .5em; }
+table.grid td { border: 1px solid #888; width: 3.5em; height: 4em; text-align: center; vertical-align: top; }
+table.grid td.none { background: #ccc; }
+.glyph { font-size: 200%; line-height: 1.4; }
+.abbr { display: inline-block; margin-top: .6em; padding: 0 .2em; border: 1px dashed #444; font-size: 70%; }
+.code { font-family: monospace; font-size: 70%; }
+table.names td { padding: .1em .6em; vertical-align: top; }
+table.names td.code { font-size: 100%; }
+.note { color: #444; font-size: 90%; }
+`
+
+// chart prints an HTML code chart of the block in the style of the Unicode
+// code charts: a grid of 16 rows, one column for each 16 code points, with
+// the glyph and code point of each character, followed by the list of names
+// with the annotations of NamesList.txt, if present.
+func chart(name string) {
+	b := lookupBlock(name)
+	title := fmt.Sprintf("%s, %04X–%04X", b.fields[0], b.lo, b.hi)
+	fmt.Printf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>%s</style>\n</head>\n<body>\n", html.EscapeString(title), chartStyle)
+	fmt.Printf("<h1>%s</h1>\n", html.EscapeString(title))
+	lo, hi := b.lo&^0xF, b.hi|0xF
+	fmt.Printf("<table class=\"grid\">\n<tr><th></th>")
+	for col := lo; col <= hi; col += 16 {
+		fmt.Printf("<th>%03X</th>", col>>4)
+	}
+	fmt.Printf("</tr>\n")
+	for row := rune(0); row < 16; row++ {
+		fmt.Printf("<tr><th>%X</th>", row)
+		for col := lo; col <= hi; col += 16 {
+			fmt.Printf("%s", chartCell(col+row, b.lo, b.hi))
+		}
+		fmt.Printf("</tr>\n")
+	}
+	fmt.Printf("</table>\n<table class=\"names\">\n")
+	for r := b.lo; r <= b.hi; r++ {
+		if !ucd.Lookup(r).Assigned() {
+			continue
+		}
+		fmt.Printf("<tr><td class=\"code\">%04X</td><td class=\"glyph\">%s</td><td>%s", r, html.EscapeString(glyph(r)), html.EscapeString(ucd.Name(r)))
+		for _, a := range annotations(r) {
+			fmt.Printf("<br><span class=\"note\">%s</span>", html.EscapeString(a))
+		}
+		fmt.Printf("</td></tr>\n")
+	}
+	fmt.Printf("</table>\n</body>\n</html>\n")
+}
+
+// chartCell returns the cell of the grid for r: its glyph, or for a
+// character with no glyph, such as a control, its abbreviation in a dashed
+// box, and its code point. Cells outside the block or unassigned are shaded.
+func chartCell(r, lo, hi rune) string {
+	if r < lo || hi < r || !ucd.Lookup(r).Assigned() {
+		return `<td class="none"></td>`
+	}
+	g := fmt.Sprintf(`<span class="glyph">%s</span>`, html.EscapeString(glyph(r)))
+	if !unicode.In(r, unicode.Mn, unicode.Me) && !unicode.IsGraphic(r) || unicode.IsSpace(r) {
+		g = fmt.Sprintf(`<span class="abbr">%s</span>`, html.EscapeString(abbreviation(r)))
+	}
+	return fmt.Sprintf(`<td title="%s">%s<br><span class="code">%04X</span></td>`, html.EscapeString(ucd.Name(r)), g, r)
+}
+
+// abbreviation returns the short form of the name of r, as shown for
+// characters with no glyph: its abbreviation alias, such as NUL or ZWJ,
+// or else the initials of its name.
+func abbreviation(r rune) string {
+	for _, a := range ucd.Aliases(r) {
+		if a.Kind == "abbreviation" {
+			return a.Name
+		}
+	}
+	var b strings.Builder
+	for _, w := range strings.Fields(strings.NewReplacer("-", " ", "<", "", ">", "").Replace(ucd.Name(r))) {
+		b.WriteByte(w[0])
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommand returns the command that copies its standard input to
+// the system clipboard, or if paste is set prints the clipboard, using the
+// tools of macOS, Windows, Wayland or X11.
+func clipboardCommand(paste bool) *exec.Cmd {
+	var copyArgs, pasteArgs []string
+	switch {
+	case runtime.GOOS == "darwin":
+		copyArgs, pasteArgs = []string{"pbcopy"}, []string{"pbpaste"}
+	case runtime.GOOS == "windows":
+		copyArgs = []string{"clip"}
+		pasteArgs = []string{"powershell", "-NoProfile", "-Command", "[Console]::OutputEncoding = [Text.Encoding]::UTF8; Get-Clipboard -Raw"}
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		copyArgs, pasteArgs = []string{"wl-copy"}, []string{"wl-paste", "--no-newline"}
+	default:
+		copyArgs, pasteArgs = []string{"xclip", "-selection", "clipboard"}, []string{"xclip", "-selection", "clipboard", "-o"}
+		if _, err := exec.LookPath("xclip"); err != nil {
+			copyArgs, pasteArgs = []string{"xsel", "--clipboard", "--input"}, []string{"xsel", "--clipboard", "--output"}
+		}
+	}
+	args := copyArgs
+	if paste {
+		args = pasteArgs
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		fatalf("no clipboard: %s", err)
+	}
+	return exec.Command(args[0], args[1:]...)
+}
+
+// copyText puts text on the clipboard.
+func copyText(text string) {
+	cmd := clipboardCommand(false)
+	if runtime.GOOS == "windows" {
+		// Clip reads the system code page unless the text is marked as UTF-16.
+		b, err := outputEncoding("utf-16le-bom").NewEncoder().String(text)
+		if err != nil {
+			fatalf("-copy: %s", err)
+		}
+		text = b
+	}
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fatalf("-copy: %s", err)
+	}
+}
+
+// pasteArgs appends the text on the clipboard to the arguments.
+func pasteArgs() {
+	cmd := clipboardCommand(true)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		fatalf("-paste: %s", err)
+	}
+	text := string(bytes.TrimRight(out, "\r\n"))
+	// Parsing again after -- keeps the flags and sets the arguments.
+	flag.CommandLine.Parse(append(append([]string{"--"}, flag.Args()...), text))
+}
@@ -0,0 +1,274 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// This file implements the Unicode Collation Algorithm of UTS #10 with the
+// Default Unicode Collation Element Table, allkeys.txt, and non-ignorable
+// variable weighting, to show the collation elements and sort key of text.
+
+var (
+	//go:embed allkeys.txt.gz
+	allkeysGz       []byte
+	collationTable  map[string][]collElem
+	maxContraction  int
+	implicitWeights []implicitWeight
+)
+
+// A collElem is a collation element: a primary, secondary and tertiary weight.
+type collElem struct {
+	w        [3]uint16
+	variable bool // Marked with * in the table.
+}
+
+func (c collElem) String() string {
+	mark := '.'
+	if c.variable {
+		mark = '*'
+	}
+	return fmt.Sprintf("[%c%04X.%04X.%04X]", mark, c.w[0], c.w[1], c.w[2])
+}
+
+// An implicitWeight is an @implicitweights line of allkeys.txt, which gives
+// the base primary weight of a range of unlisted ideographs.
+type implicitWeight struct {
+	lo, hi rune
+	start  rune // The first code point of the script, from which weights count.
+	base   uint16
+}
+
+func loadCollationTable() {
+	if collationTable != nil {
+		return
+	}
+	collationTable = make(map[string][]collElem)
+	for i, line := range splitLines(ucdText("allkeys.txt", allkeysGz)) {
+		if j := strings.IndexByte(line, '#'); j >= 0 {
+			line = line[:j]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "@implicitweights"):
+			f := strings.Split(strings.TrimPrefix(line, "@implicitweights"), ";")
+			r := strings.Split(strings.TrimSpace(f[0]), "..")
+			base, err := strconv.ParseUint(strings.TrimSpace(f[1]), 16, 16)
+			if len(r) != 2 || err != nil {
+				fatalf("malformed allkeys.txt: line %d", i+1)
+			}
+			w := implicitWeight{lo: parseRune(r[0]), hi: parseRune(r[1]), base: uint16(base)}
+			w.start = w.lo
+			for _, v := range implicitWeights {
+				if v.base == w.base && v.start < w.start {
+					w.start = v.start
+				}
+			}
+			implicitWeights = append(implicitWeights, w)
+			continue
+		case line[0] == '@':
+			continue
+		}
+		semi := strings.IndexByte(line, ';')
+		if semi < 0 {
+			fatalf("malformed allkeys.txt: line %d", i+1)
+		}
+		var codes []rune
+		for _, f := range strings.Fields(line[:semi]) {
+			codes = append(codes, parseRune(f))
+		}
+		var elems []collElem
+		for _, e := range strings.Split(line[semi+1:], "]")[:strings.Count(line[semi+1:], "]")] {
+			e = strings.TrimSpace(e)
+			if len(e) < 2 || e[0] != '[' {
+				fatalf("malformed allkeys.txt: line %d", i+1)
+			}
+			var c collElem
+			c.variable = e[1] == '*'
+			for k, w := range strings.Split(e[2:], ".")[:3] {
+				n, err := strconv.ParseUint(w, 16, 16)
+				if err != nil {
+					fatalf("malformed allkeys.txt: line %d", i+1)
+				}
+				c.w[k] = uint16(n)
+			}
+			elems = append(elems, c)
+		}
+		collationTable[string(codes)] = elems
+		if len(codes) > maxContraction {
+			maxContraction = len(codes)
+		}
+	}
+}
+
+// implicitElems returns the collation elements derived for r, which is not
+// in the table, as described in section 10.1 of UTS #10.
+func implicitElems(r rune) []collElem {
+	base, start := uint16(0xFBC0), rune(-1)
+	for _, w := range implicitWeights {
+		if w.lo <= r && r <= w.hi {
+			base, start = w.base, w.start
+		}
+	}
+	switch {
+	case start >= 0:
+		// A script with its own range of weights.
+	case unicode.Is(unicode.Unified_Ideograph, r) && (0x4E00 <= r && r <= 0x9FFF || 0xF900 <= r && r <= 0xFAFF):
+		base = 0xFB40
+	case unicode.Is(unicode.Unified_Ideograph, r):
+		base = 0xFB80
+	}
+	var aaaa, bbbb uint16
+	if start >= 0 {
+		aaaa, bbbb = base, uint16(r-start)|0x8000
+	} else {
+		aaaa, bbbb = base+uint16(r>>15), uint16(r&0x7FFF)|0x8000
+	}
+	return []collElem{{w: [3]uint16{aaaa, 0x0020, 0x0002}}, {w: [3]uint16{bbbb, 0, 0}}}
+}
+
+// A collMatch is the collation elements of a run of characters of the text.
+type collMatch struct {
+	text  string
+	elems []collElem
+}
+
+func combiningClass(r rune) uint8 {
+	return norm.NFD.PropertiesString(string(r)).CCC()
+}
+
+// collationElements returns the collation elements of s, grouped by the
+// characters, or contractions of characters, that produce them.
+func collationElements(s string) []collMatch {
+	loadCollationTable()
+	runes := []rune(norm.NFD.String(s))
+	var matches []collMatch
+	for len(runes) > 0 {
+		// Find the longest contiguous match (S2.1).
+		n := maxContraction
+		if n > len(runes) {
+			n = len(runes)
+		}
+		for ; n > 1; n-- {
+			if _, ok := collationTable[string(runes[:n])]; ok {
+				break
+			}
+		}
+		match := string(runes[:n])
+		elems, ok := collationTable[match]
+		if !ok {
+			matches = append(matches, collMatch{match, implicitElems(runes[0])})
+			runes = runes[1:]
+			continue
+		}
+		rest := runes[n:]
+		// Extend the match with unblocked non-starters (S2.1.1 to S2.1.3).
+		var last uint8
+		for k := 0; k < len(rest); {
+			ccc := combiningClass(rest[k])
+			if ccc == 0 {
+				break
+			}
+			if e, ok := collationTable[match+string(rest[k])]; ok && (k == 0 || last < ccc) {
+				match += string(rest[k])
+				elems = e
+				rest = append(rest[:k:k], rest[k+1:]...)
+				continue
+			}
+			last = ccc
+			k++
+		}
+		matches = append(matches, collMatch{match, elems})
+		runes = rest
+	}
+	return matches
+}
+
+// sortKey returns the sort key for the collation elements: the nonzero
+// weights of each level in turn, with levels separated by 0000 (S3).
+func sortKey(matches []collMatch) []uint16 {
+	var key []uint16
+	for level := 0; level < 3; level++ {
+		if level > 0 {
+			key = append(key, 0)
+		}
+		for _, m := range matches {
+			for _, e := range m.elems {
+				if e.w[level] != 0 {
+					key = append(key, e.w[level])
+				}
+			}
+		}
+	}
+	return key
+}
+
+// showSortKeys prints, for each argument, the collation elements of its
+// characters and its sort key.
+func showSortKeys(args []string) {
+	for _, a := range argsOrStdin(args) {
+		matches := collationElements(a)
+		fmt.Printf("'%s'\n", a)
+		for _, m := range matches {
+			var elems []string
+			for _, e := range m.elems {
+				elems = append(elems, e.String())
+			}
+			fmt.Printf("\t%s\t%s\n", codePoints(m.text), strings.Join(elems, ""))
+		}
+		var key []string
+		for _, w := range sortKey(matches) {
+			key = append(key, fmt.Sprintf("%04X", w))
+		}
+		fmt.Printf("\tsort key: %s\n", strings.Join(key, " "))
+	}
+}
+
+// sortLines prints the arguments, or the lines of standard input if there
+// are none, sorted by their sort keys. If locale is set, the lines are
+// sorted instead by golang.org/x/text/collate with the CLDR tailoring for
+// that locale.
+func sortLines(args []string, locale string) {
+	lines := argsOrStdin(args)
+	if locale != "" {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			fatalf("-locale: %s", err)
+		}
+		collate.New(tag).SortStrings(lines)
+	} else {
+		keys := make(map[string][]uint16)
+		for _, l := range lines {
+			keys[l] = sortKey(collationElements(l))
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			a, b := keys[lines[i]], keys[lines[j]]
+			for k := 0; k < len(a) && k < len(b); k++ {
+				if a[k] != b[k] {
+					return a[k] < b[k]
+				}
+			}
+			if len(a) != len(b) {
+				return len(a) < len(b)
+			}
+			return lines[i] < lines[j] // Break ties by code point order.
+		})
+	}
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+// terminalWidth returns the width of the terminal on standard output, or
+// if that is not a terminal, $COLUMNS or 80.
+func terminalWidth() int {
+	if w := ttyWidth(os.Stdout); w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 80
+}
+
+// glyph returns a printable form of r: combining marks are shown on a
+// dotted circle and characters with no glyph, such as controls, as a space.
+func glyph(r rune) string {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return "◌" + string(r)
+	case !unicode.IsPrint(r):
+		return " "
+	}
+	return string(r)
+}
+
+// outputColumns prints codes in a grid as wide as the terminal, each
+// character above its code point.
+func outputColumns(codes []rune) {
+	cell := 4
+	for _, r := range codes {
+		if n := len(fmt.Sprintf("%04X", r)); n > cell {
+			cell = n
+		}
+	}
+	cell += 2 // Space between columns.
+	perRow := terminalWidth() / cell
+	if perRow < 1 {
+		perRow = 1
+	}
+	for i := 0; i < len(codes); i += perRow {
+		row := codes[i:]
+		if len(row) > perRow {
+			row = row[:perRow]
+		}
+		var glyphs, hex strings.Builder
+		for _, r := range row {
+			g := glyph(r)
+			pad := cell - uniseg.StringWidth(g)
+			if pad < 1 {
+				pad = 1
+			}
+			glyphs.WriteString(g + strings.Repeat(" ", pad))
+			fmt.Fprintf(&hex, "%-*s", cell, fmt.Sprintf("%04X", r))
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(strings.TrimRight(glyphs.String(), " "))
+		fmt.Println(strings.TrimRight(hex.String(), " "))
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// cccNames holds the names of the canonical combining classes, from
+// PropertyValueAliases.txt. The fixed position classes 10 to 199 are
+// named by number, such as CCC10.
+var cccNames = map[int]string{
+	0:   "Not_Reordered",
+	1:   "Overlay",
+	6:   "Han_Reading",
+	7:   "Nukta",
+	8:   "Kana_Voicing",
+	9:   "Virama",
+	200: "Attached_Below_Left",
+	202: "Attached_Below",
+	214: "Attached_Above",
+	216: "Attached_Above_Right",
+	218: "Below_Left",
+	220: "Below",
+	222: "Below_Right",
+	224: "Left",
+	226: "Right",
+	228: "Above_Left",
+	230: "Above",
+	232: "Above_Right",
+	233: "Double_Below",
+	234: "Double_Above",
+	240: "Iota_Subscript",
+}
+
+// cccName returns the name of the canonical combining class given in decimal.
+func cccName(s string) string {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return "?"
+	}
+	if name, ok := cccNames[n]; ok {
+		return name
+	}
+	if 10 <= n && n <= 199 {
+		return "CCC" + s
+	}
+	return "?"
+}
+
+// compose prints each argument, a base character followed by combining
+// marks, as a cluster with the combining class of each of its characters,
+// then its NFC form and whether that is a single precomposed character.
+func compose(args []string) {
+	for _, a := range args {
+		fmt.Printf("'%s'\t%s\n", a, codePoints(a))
+		for _, r := range a {
+			fields := strings.Split(lookup(r), ";")
+			ccc := "0"
+			if len(fields) > 2 {
+				ccc = fields[2]
+			}
+			fmt.Printf("\t%U '%c' %s: ccc %s %s\n", r, r, ucd.Name(r), ccc, cccName(ccc))
+		}
+		c := norm.NFC.String(a)
+		switch {
+		case utf8.RuneCountInString(c) == 1 && c != a:
+			fmt.Printf("\tNFC: '%s' %s, precomposed\n", c, codePoints(c))
+		case utf8.RuneCountInString(c) == 1:
+			fmt.Printf("\tNFC: unchanged\n")
+		default:
+			fmt.Printf("\tNFC: '%s' %s, no single precomposed form\n", c, codePoints(c))
+		}
+	}
+}
+
+// The constants of the algorithmic composition of Hangul syllables.
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// composeHangul returns the Hangul syllable composed of a and b, a leading
+// consonant and a vowel or a syllable with no trailing consonant and a
+// trailing consonant, and whether there is one.
+func composeHangul(a, b rune) (rune, bool) {
+	switch {
+	case hangulLBase <= a && a < hangulLBase+hangulLCount && hangulVBase <= b && b < hangulVBase+hangulVCount:
+		return hangulSBase + ((a-hangulLBase)*hangulVCount+b-hangulVBase)*hangulTCount, true
+	case hangulSBase <= a && a < hangulSBase+hangulSCount && (a-hangulSBase)%hangulTCount == 0 &&
+		hangulTBase < b && b < hangulTBase+hangulTCount:
+		return a + b - hangulTBase, true
+	}
+	return 0, false
+}
+
+// composePair prints the primary composite of the pair of characters given
+// by the two arguments, code points in hex or single characters: the
+// character whose canonical decomposition is the pair and that is not
+// excluded from composition, or why there is none.
+func composePair(args []string) {
+	if len(args) != 2 {
+		fatalf("-compose-pair takes two code points, such as 0065 0301")
+	}
+	var pair [2]rune
+	for i, a := range args {
+		switch hex := strings.TrimPrefix(strings.ToUpper(a), "U+"); {
+		case hex != "" && strings.Trim(hex, "0123456789ABCDEF") == "":
+			pair[i] = parseRune(hex)
+		case utf8.RuneCountInString(a) == 1:
+			pair[i], _ = utf8.DecodeRuneInString(a)
+		default:
+			fatalf("-compose-pair: %q is not a code point or a character", a)
+		}
+	}
+	a, b := pair[0], pair[1]
+	fmt.Printf("%#U %s + %#U %s\n", a, ucd.Name(a), b, ucd.Name(b))
+	if c, ok := composeHangul(a, b); ok {
+		fmt.Printf("\tcomposes to %#U %s, by the Hangul composition algorithm\n", c, ucd.Name(c))
+		return
+	}
+	excluded := lookupProp("Full_Composition_Exclusion")
+	found := false
+	ucd.Each(func(e ucd.Entry) {
+		if e.DecompTag != "" || len(e.Decomposition) != 2 || e.Decomposition[0] != a || e.Decomposition[1] != b {
+			return
+		}
+		found = true
+		switch {
+		case !excluded(e.Code):
+			fmt.Printf("\tcomposes to %#U %s\n", e.Code, e.Name)
+		case e.CombiningClass != 0 || ucd.Lookup(a).CombiningClass != 0:
+			fmt.Printf("\tno composite: %#U %s decomposes to the pair, but is excluded from composition as a non-starter decomposition\n", e.Code, e.Name)
+		default:
+			fmt.Printf("\tno composite: %#U %s decomposes to the pair, but is excluded from composition by CompositionExclusions.txt\n", e.Code, e.Name)
+		}
+	})
+	if !found {
+		fmt.Printf("\tno composite: no character decomposes canonically to the pair\n")
+	}
+}
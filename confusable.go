@@ -0,0 +1,128 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// The confusable mappings of Unicode Technical Standard #39, from
+// confusables.txt in the security directory of the Unicode site,
+// are read from ucdDir.
+var (
+	confusables  map[rune]string   // Maps a character to its prototype.
+	confusableOf map[string][]rune // Maps a skeleton to the characters that have it.
+)
+
+func loadConfusables() {
+	if confusables != nil {
+		return
+	}
+	text := readUCD("confusables.txt")
+	if text == "" {
+		fatalf("no confusable data: confusables.txt not found in %s", ucdDir())
+	}
+	text = strings.TrimPrefix(text, "\uFEFF")
+	confusables = make(map[rune]string)
+	for i, line := range splitLines(text) {
+		if j := strings.IndexByte(line, '#'); j >= 0 {
+			line = line[:j]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			fatalf("malformed confusables.txt: line %d", i+1)
+		}
+		var codes []rune
+		for _, f := range strings.Fields(fields[1]) {
+			codes = append(codes, parseRune(f))
+		}
+		confusables[parseRune(strings.TrimSpace(fields[0]))] = string(codes)
+	}
+	confusableOf = make(map[string][]rune)
+	add := func(r rune) {
+		s := skeleton(string(r))
+		for _, c := range confusableOf[s] {
+			if c == r {
+				return
+			}
+		}
+		confusableOf[s] = append(confusableOf[s], r)
+	}
+	for r, proto := range confusables {
+		add(r)
+		for _, c := range proto {
+			add(c)
+		}
+	}
+	for _, list := range confusableOf {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+}
+
+// skeleton returns the skeleton of s as defined by UTS #39: the NFD form
+// of s with each character replaced by its prototype, then put in NFD again.
+// Two strings are confusable if they have the same skeleton.
+func skeleton(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if p, ok := confusables[r]; ok {
+			b.WriteString(p)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFD.String(b.String())
+}
+
+// confuse prints the skeleton of each argument and, for each of its
+// characters, the characters that are confusable with it.
+func confuse(args []string) {
+	loadConfusables()
+	for _, a := range args {
+		s := skeleton(a)
+		fmt.Printf("'%s' skeleton '%s' %s\n", a, s, codePoints(s))
+		indent := "\t"
+		if len([]rune(a)) > 1 {
+			indent = "\t\t"
+		}
+		seen := make(map[rune]bool)
+		for _, r := range a {
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			if indent != "\t" {
+				fmt.Printf("\t%#U\n", r)
+			}
+			for _, c := range confusableOf[skeleton(string(r))] {
+				fmt.Printf("%s%#U %s\n", indent, c, ucd.Name(c))
+			}
+		}
+	}
+}
+
+// confusablesOf returns the characters other than r that are confusable
+// with it, or nil if confusables.txt has not been installed by -update.
+func confusablesOf(r rune) []rune {
+	if confusables == nil && readUCD("confusables.txt") == "" {
+		return nil
+	}
+	loadConfusables()
+	var list []rune
+	for _, c := range confusableOf[skeleton(string(r))] {
+		if c != r {
+			list = append(list, c)
+		}
+	}
+	return list
+}
@@ -0,0 +1,91 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// decompose prints, for each code point, the tree of its recursive
+// decomposition as given by the decomposition field of UnicodeData.txt, followed by the
+// fully expanded canonical and compatibility decompositions.
+func decompose(codes []rune) {
+	for _, r := range codes {
+		decompTree(r, "", 0)
+		s := string(r)
+		if d := norm.NFD.String(s); d != s {
+			fmt.Printf("\tcanonical: %s\n", codePoints(d))
+		}
+		if d := norm.NFKD.String(s); d != norm.NFD.String(s) {
+			fmt.Printf("\tcompatibility: %s\n", codePoints(d))
+		}
+	}
+}
+
+// decompTree prints r, indented by depth, with the formatting tag of the
+// decomposition that produced it, then the characters r decomposes into.
+func decompTree(r rune, tag string, depth int) {
+	fields := strings.Split(lookup(r), ";")
+	if tag != "" {
+		tag = " " + tag
+	}
+	fmt.Printf("%s%#U %s%s\n", strings.Repeat("\t", depth), r, ucd.Name(r), tag)
+	tag, parts := decomposition(r, fields)
+	for _, c := range parts {
+		decompTree(c, tag, depth+1)
+	}
+}
+
+// decomposition returns the formatting tag, such as <compat>, and the
+// code points of the single-step decomposition of r. Hangul syllables,
+// which have no decomposition field, are decomposed algorithmically.
+func decomposition(r rune, fields []string) (string, []rune) {
+	if len(fields) > 4 && fields[4] != "" {
+		var tag string
+		var parts []rune
+		for _, f := range strings.Fields(fields[4]) {
+			if f[0] == '<' {
+				tag = f
+				continue
+			}
+			parts = append(parts, parseRune(f))
+		}
+		return tag, parts
+	}
+	if strings.HasPrefix(fields[0], "HANGUL SYLLABLE ") {
+		return "", []rune(norm.NFD.String(string(r)))
+	}
+	return "", nil
+}
+
+// argsAreVariants returns, for each character of the arguments, the
+// characters whose full canonical or compatibility decomposition contains
+// it, such as é, ℯ and ᵉ for e, in code point order.
+func argsAreVariants() []rune {
+	bases := make(map[rune]bool)
+	for _, a := range flag.Args() {
+		for _, r := range a {
+			bases[r] = true
+		}
+	}
+	var codes []rune
+	ucd.Each(func(e ucd.Entry) {
+		if bases[e.Code] {
+			return
+		}
+		for _, r := range norm.NFKD.String(string(e.Code)) {
+			if bases[r] {
+				codes = append(codes, e.Code)
+				return
+			}
+		}
+	})
+	return codes
+}
@@ -0,0 +1,164 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// The properties of DerivedCoreProperties.txt are computed from their
+// definitions, which are given in the header of that file, using the
+// tables of package unicode and the case and normalization data of
+// golang.org/x/text.
+
+var (
+	isMath         = isIn(unicode.Sm, unicode.Other_Math)
+	isLowercase    = isIn(unicode.Ll, unicode.Other_Lowercase)
+	isUppercase    = isIn(unicode.Lu, unicode.Other_Uppercase)
+	isGraphemeExt  = isIn(unicode.Me, unicode.Mn, unicode.Other_Grapheme_Extend)
+	isPatternSpace = isIn(unicode.Pattern_Syntax, unicode.Pattern_White_Space)
+)
+
+// wordBreakMid holds the characters with Word_Break values MidLetter,
+// MidNumLet and Single_Quote, which are Case_Ignorable.
+var wordBreakMid = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0027, 0x0027, 1},
+		{0x002e, 0x002e, 1},
+		{0x003a, 0x003a, 1},
+		{0x00b7, 0x00b7, 1},
+		{0x0387, 0x0387, 1},
+		{0x055f, 0x055f, 1},
+		{0x05f4, 0x05f4, 1},
+		{0x2018, 0x2019, 1},
+		{0x2024, 0x2024, 1},
+		{0x2027, 0x2027, 1},
+		{0xfe13, 0xfe13, 1},
+		{0xfe52, 0xfe52, 1},
+		{0xfe55, 0xfe55, 1},
+		{0xff07, 0xff07, 1},
+		{0xff0e, 0xff0e, 1},
+		{0xff1a, 0xff1a, 1},
+	},
+	LatinOffset: 4,
+}
+
+// notIgnorable holds the format characters that are excluded from
+// Default_Ignorable_Code_Point: the interlinear annotation characters
+// and the Egyptian hieroglyph format controls.
+var notIgnorable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0xfff9, 0xfffb, 1},
+	},
+	R32: []unicode.Range32{
+		{0x13430, 0x1343f, 1},
+	},
+}
+
+func isAlphabetic(r rune) bool {
+	return isLowercase(r) || isUppercase(r) ||
+		unicode.In(r, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl, unicode.Other_Alphabetic)
+}
+
+func isCased(r rune) bool {
+	return isLowercase(r) || isUppercase(r) || unicode.Is(unicode.Lt, r)
+}
+
+func isCaseIgnorable(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Lm, unicode.Sk, wordBreakMid)
+}
+
+func isIDStart(r rune) bool {
+	return unicode.In(r, unicode.L, unicode.Nl, unicode.Other_ID_Start) && !isPatternSpace(r)
+}
+
+func isIDContinue(r rune) bool {
+	return (isIDStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue)) &&
+		!isPatternSpace(r)
+}
+
+// isXIDStart and isXIDContinue are ID_Start and ID_Continue restricted to
+// the characters whose NFKC form is still a valid identifier fragment.
+func isXIDStart(r rune) bool {
+	if !isIDStart(r) {
+		return false
+	}
+	for i, c := range norm.NFKC.String(string(r)) {
+		if i == 0 && !isIDStart(c) || !isIDContinue(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isXIDContinue(r rune) bool {
+	if !isIDContinue(r) {
+		return false
+	}
+	for _, c := range norm.NFKC.String(string(r)) {
+		if !isIDContinue(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDefaultIgnorable(r rune) bool {
+	return unicode.In(r, unicode.Other_Default_Ignorable_Code_Point, unicode.Cf, unicode.Variation_Selector) &&
+		!unicode.In(r, unicode.White_Space, notIgnorable, unicode.Prepended_Concatenation_Mark)
+}
+
+func isGraphemeBase(r rune) bool {
+	return unicode.IsGraphic(r) && !isGraphemeExt(r)
+}
+
+func isGraphemeLink(r rune) bool {
+	return norm.NFD.PropertiesString(string(r)).CCC() == 9
+}
+
+// changesWhen returns a test for whether the mapping of a character
+// changes its NFD form.
+func changesWhen(c cases.Caser) func(rune) bool {
+	return func(r rune) bool {
+		s := norm.NFD.String(string(r))
+		return c.String(s) != s
+	}
+}
+
+var (
+	changesWhenLowercased = changesWhen(cases.Lower(language.Und))
+	changesWhenUppercased = changesWhen(cases.Upper(language.Und))
+	changesWhenTitlecased = changesWhen(cases.Title(language.Und, cases.NoLower))
+)
+
+func changesWhenCasemapped(r rune) bool {
+	return changesWhenLowercased(r) || changesWhenUppercased(r) || changesWhenTitlecased(r)
+}
+
+func init() {
+	addProp("Math", isMath)
+	addProp("Alphabetic", isAlphabetic)
+	addProp("Lowercase", isLowercase)
+	addProp("Uppercase", isUppercase)
+	addProp("Cased", isCased)
+	addProp("Case_Ignorable", isCaseIgnorable)
+	addProp("Changes_When_Lowercased", changesWhenLowercased)
+	addProp("Changes_When_Uppercased", changesWhenUppercased)
+	addProp("Changes_When_Titlecased", changesWhenTitlecased)
+	addProp("Changes_When_Casefolded", changesWhen(cases.Fold()))
+	addProp("Changes_When_Casemapped", changesWhenCasemapped)
+	addProp("ID_Start", isIDStart)
+	addProp("ID_Continue", isIDContinue)
+	addProp("XID_Start", isXIDStart)
+	addProp("XID_Continue", isXIDContinue)
+	addProp("Default_Ignorable_Code_Point", isDefaultIgnorable)
+	addProp("Grapheme_Extend", isGraphemeExt)
+	addProp("Grapheme_Base", isGraphemeBase)
+	addProp("Grapheme_Link", isGraphemeLink)
+}
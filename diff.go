@@ -0,0 +1,115 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// versionEntries returns the entries of UnicodeData.txt of Unicode version
+// v, read from its directory in the cache if -ucd has fetched it, or else
+// downloaded.
+func versionEntries(v string) map[rune]ucd.Entry {
+	saved := ucdVersion
+	defer func() { ucdVersion = saved }()
+	ucdVersion = fullVersion(v)
+	text := readUCD("UnicodeData.txt")
+	if text == "" {
+		data, err := fetch(versionURLs("UCD/latest/ucd/UnicodeData.txt")[0])
+		if err != nil {
+			fatalf("-diff: %s", err)
+		}
+		text = string(data)
+	}
+	m := make(map[rune]ucd.Entry)
+	if err := ucd.Parse(text, func(e ucd.Entry) { m[e.Code] = e }); err != nil {
+		fatalf("-diff: Unicode %s: %s", ucdVersion, err)
+	}
+	return m
+}
+
+// diff prints the characters added, removed, renamed or otherwise changed
+// in UnicodeData.txt between the two versions of Unicode in args,
+// restricted by -block, -range and -cat.
+func diff(args []string) {
+	if len(args) != 2 {
+		fatalf("-diff: want two versions, such as 15.0 16.0")
+	}
+	old, new := versionEntries(args[0]), versionEntries(args[1])
+	var codes []rune
+	for r := range new {
+		codes = append(codes, r)
+	}
+	for r := range old {
+		if _, ok := new[r]; !ok {
+			codes = append(codes, r)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	if *doRange != "" {
+		codes = inRanges(codes, *doRange, false)
+	}
+	if *doBlock != "" {
+		codes = inRanges(codes, *doBlock, true)
+	}
+	var cats []string
+	if *doCat != "" {
+		cats = strings.Split(*doCat, ",")
+		for _, c := range cats {
+			if unicode.Categories[c] == nil {
+				fatalf("unknown category %q", c)
+			}
+		}
+	}
+	for _, r := range codes {
+		o, inOld := old[r]
+		n, inNew := new[r]
+		e := n
+		if !inNew {
+			e = o
+		}
+		if cats != nil && !inCategory(o, cats) && !inCategory(e, cats) {
+			continue
+		}
+		switch {
+		case !inOld:
+			fmt.Printf("%U added: %s (%s)\n", r, n.Name, n.Category)
+		case !inNew:
+			fmt.Printf("%U removed: %s\n", r, o.Name)
+		default:
+			if changes := entryChanges(o, n); len(changes) > 0 {
+				fmt.Printf("%U %s: %s\n", r, o.Name, strings.Join(changes, "; "))
+			}
+		}
+	}
+}
+
+// inCategory reports whether the general category of e is one of cats.
+func inCategory(e ucd.Entry, cats []string) bool {
+	for _, c := range cats {
+		if e.Category == c || len(c) == 1 && strings.HasPrefix(e.Category, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// entryChanges describes the fields that differ between o and n, such as
+// "category Ll → Lo", using the names of -field.
+func entryChanges(o, n ucd.Entry) []string {
+	of, nf := strings.Split(o.String(), ";"), strings.Split(n.String(), ";")
+	var changes []string
+	for i := range of {
+		if of[i] != nf[i] {
+			changes = append(changes, fmt.Sprintf("%s %q → %q", fieldNames[i+1], of[i], nf[i]))
+		}
+	}
+	return changes
+}
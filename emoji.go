@@ -0,0 +1,41 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+)
+
+var (
+	//go:embed emoji-data.txt.gz
+	emojiDataGz []byte
+	emojiProps  map[string][]rangeValue
+)
+
+func init() {
+	for _, name := range []string{
+		"Emoji",
+		"Emoji_Presentation",
+		"Emoji_Modifier",
+		"Emoji_Modifier_Base",
+		"Emoji_Component",
+		"Extended_Pictographic",
+	} {
+		addProp(name, isEmojiProp(name))
+	}
+}
+
+// isEmojiProp returns the membership test for the named property of emoji-data.txt.
+func isEmojiProp(name string) func(rune) bool {
+	return func(r rune) bool {
+		if emojiProps == nil {
+			emojiProps = make(map[string][]rangeValue)
+			for _, v := range parseRanges(ucdText("emoji-data.txt", emojiDataGz)) {
+				emojiProps[v.fields[0]] = append(emojiProps[v.fields[0]], v)
+			}
+		}
+		return lookupRange(emojiProps[name], r) != nil
+	}
+}
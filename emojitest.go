@@ -0,0 +1,158 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	//go:embed emoji-test.txt.gz
+	emojiTestGz []byte
+	emojiList   []emoji
+)
+
+// An emoji is an entry of emoji-test.txt, which lists the emoji,
+// most of them sequences, in CLDR order with their CLDR short names.
+type emoji struct {
+	text     string
+	name     string
+	status   string // Such as fully-qualified or component.
+	version  string // The emoji version, such as E15.0.
+	group    string
+	subgroup string
+}
+
+func loadEmojiTest() {
+	if emojiList != nil {
+		return
+	}
+	var group, subgroup string
+	for i, line := range splitLines(ucdText("emoji-test.txt", emojiTestGz)) {
+		switch {
+		case strings.HasPrefix(line, "# group: "):
+			group = line[len("# group: "):]
+			continue
+		case strings.HasPrefix(line, "# subgroup: "):
+			subgroup = line[len("# subgroup: "):]
+			continue
+		case line == "" || line[0] == '#':
+			continue
+		}
+		semi := strings.IndexByte(line, ';')
+		hash := strings.IndexByte(line, '#')
+		if semi < 0 || hash < semi {
+			fatalf("malformed emoji-test.txt: line %d", i+1)
+		}
+		var codes []rune
+		for _, f := range strings.Fields(line[:semi]) {
+			codes = append(codes, parseRune(f))
+		}
+		// The comment is the emoji, its version and its name.
+		comment := strings.SplitN(strings.TrimSpace(line[hash+1:]), " ", 3)
+		if len(comment) < 3 {
+			fatalf("malformed emoji-test.txt: line %d", i+1)
+		}
+		emojiList = append(emojiList, emoji{
+			text:     string(codes),
+			name:     comment[2],
+			status:   strings.TrimSpace(line[semi+1 : hash]),
+			version:  comment[1],
+			group:    group,
+			subgroup: subgroup,
+		})
+	}
+}
+
+// emojiKeywords maps an emoji, without variation selectors, to its CLDR
+// keywords. It is read from annotations/en.xml in ucdDir, if present.
+var emojiKeywords map[string]string
+
+var annotationRE = regexp.MustCompile(`<annotation cp="([^"]+)">([^<]*)</annotation>`)
+
+func loadEmojiKeywords() {
+	if emojiKeywords != nil {
+		return
+	}
+	emojiKeywords = make(map[string]string)
+	for _, m := range annotationRE.FindAllStringSubmatch(readUCD("annotations/en.xml"), -1) {
+		emojiKeywords[m[1]] = strings.ReplaceAll(m[2], " | ", "; ")
+	}
+}
+
+// argsAreEmojiRegexps returns the fully-qualified emoji whose short names or
+// keywords match the argument regexps.
+func argsAreEmojiRegexps() []namedSeq {
+	loadEmojiTest()
+	loadEmojiKeywords()
+	var seqs []namedSeq
+	for _, a := range flag.Args() {
+		re, err := regexp.Compile(a)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		for _, e := range emojiList {
+			if e.status != "fully-qualified" {
+				continue
+			}
+			line := e.name
+			if k := emojiKeywords[strings.ReplaceAll(e.text, "\uFE0F", "")]; k != "" {
+				line += "; " + k
+			}
+			if re.MatchString(strings.ToLower(line)) {
+				seqs = append(seqs, namedSeq{e.name, e.text})
+			}
+		}
+	}
+	return seqs
+}
+
+// emojiCatalog prints the emoji of emoji-test.txt in CLDR order, the order
+// of emoji pickers, under the headings of their groups and subgroups, with
+// their code points, names, qualification status and emoji versions. If
+// there are args, they are regular expressions, and only the groups and
+// subgroups whose names match one of them are printed.
+func emojiCatalog(args []string) {
+	loadEmojiTest()
+	var res []*regexp.Regexp
+	for _, a := range args {
+		re, err := regexp.Compile(a)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		res = append(res, re)
+	}
+	selected := func(e emoji) bool {
+		for _, re := range res {
+			if re.MatchString(strings.ToLower(e.group)) || re.MatchString(strings.ToLower(e.subgroup)) {
+				return true
+			}
+		}
+		return len(res) == 0
+	}
+	defer stdout.Flush()
+	var group, subgroup string
+	for _, e := range emojiList {
+		if !selected(e) {
+			continue
+		}
+		if e.group != group {
+			if group != "" {
+				fmt.Fprintln(stdout)
+			}
+			group, subgroup = e.group, ""
+			fmt.Fprintf(stdout, "%s\n", group)
+		}
+		if e.subgroup != subgroup {
+			subgroup = e.subgroup
+			fmt.Fprintf(stdout, "\t%s\n", subgroup)
+		}
+		fmt.Fprintf(stdout, "\t\t%s '%s' %s; %s, %s\n", codePoints(e.text), e.text, e.name, e.status, e.version)
+	}
+}
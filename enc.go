@@ -0,0 +1,148 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+	"golang.org/x/text/transform"
+)
+
+// encodingAliases holds common names of encodings that neither the IANA
+// nor the WHATWG index knows.
+var encodingAliases = map[string]string{
+	"shiftjis": "Shift_JIS",
+	"eucjp":    "EUC-JP",
+	"euckr":    "EUC-KR",
+	"utf8":     "UTF-8",
+	"utf16":    "UTF-16",
+	"utf16le":  "UTF-16LE",
+	"utf16be":  "UTF-16BE",
+}
+
+// lookupEncoding returns the encoding with the name, such as latin1,
+// windows-1252 or gb18030, by IANA's names or else the WHATWG's.
+func lookupEncoding(name string) encoding.Encoding {
+	if alias, ok := encodingAliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+	if e, err := ianaindex.IANA.Encoding(name); err == nil && e != nil {
+		return e
+	}
+	if e, err := htmlindex.Get(name); err == nil {
+		return e
+	}
+	fatalf("unknown encoding %q", name)
+	return nil
+}
+
+// outputEncodings prints the bytes of each of codes in the encodings of
+// the comma-separated list names, or notes that it has none.
+func outputEncodings(names string, codes []rune) {
+	list := strings.Split(names, ",")
+	encs := make([]encoding.Encoding, len(list))
+	for i, name := range list {
+		encs[i] = lookupEncoding(name)
+	}
+	for _, r := range codes {
+		var f []string
+		for i, e := range encs {
+			b, err := e.NewEncoder().Bytes([]byte(string(r)))
+			if err != nil {
+				f = append(f, list[i]+": unrepresentable")
+				continue
+			}
+			f = append(f, fmt.Sprintf("%s: % x", list[i], b))
+		}
+		fmt.Fprintf(stdout, "%#U %s\n", r, strings.Join(f, "; "))
+	}
+	stdout.Flush()
+}
+
+// outputEncoding returns the encoding named by -oenc: an encoding known to
+// lookupEncoding, or UTF-8, UTF-16LE, UTF-16BE, UTF-32LE or UTF-32BE with
+// a byte order mark if the name ends in bom, as in utf-16le-bom.
+func outputEncoding(name string) encoding.Encoding {
+	lower := strings.ToLower(name)
+	if !strings.HasSuffix(lower, "bom") {
+		return lookupEncoding(name)
+	}
+	switch strings.TrimRight(strings.TrimSuffix(lower, "bom"), "-_+ ") {
+	case "utf-8", "utf8":
+		return unicode.UTF8BOM
+	case "utf-16le", "utf16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case "utf-16be", "utf16be", "utf-16", "utf16":
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	case "utf-32le", "utf32le":
+		return utf32.UTF32(utf32.LittleEndian, utf32.UseBOM)
+	case "utf-32be", "utf32be", "utf-32", "utf32":
+		return utf32.UTF32(utf32.BigEndian, utf32.UseBOM)
+	}
+	fatalf("-oenc: no byte order mark for %q", name)
+	return nil
+}
+
+// finishOutput, if set, completes the output redirected by redirectOutput.
+var finishOutput func()
+
+// redirectOutput sends standard output to the named file, or if name is
+// "" to standard output itself, encoded by the encoding named encName, or
+// in UTF-8 if that is "". Everything written to os.Stdout passes through
+// a pipe to a goroutine that encodes it, and finishOutput waits for that.
+func redirectOutput(name, encName string) {
+	out := os.Stdout
+	if name != "" {
+		f, err := os.Create(name)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		out = f
+	}
+	var w io.Writer = out
+	var enc *transform.Writer
+	if encName != "" {
+		enc = transform.NewWriter(out, outputEncoding(encName).NewEncoder())
+		w = enc
+	}
+	r, pw, err := os.Pipe()
+	if err != nil {
+		fatalf("%s", err)
+	}
+	os.Stdout = pw
+	stdout = bufio.NewWriter(pw)
+	done := make(chan error)
+	go func() {
+		_, err := io.Copy(w, r)
+		if enc != nil && err == nil {
+			err = enc.Close() // Flush the encoder; finishOutput closes the file.
+		}
+		io.Copy(io.Discard, r) // Drain the pipe after an error.
+		done <- err
+	}()
+	finishOutput = func() {
+		finishOutput = nil
+		stdout.Flush()
+		pw.Close()
+		err := <-done
+		if name != "" {
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			fatalf("output: %s", err)
+		}
+	}
+}
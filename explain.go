@@ -0,0 +1,77 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// explain prints each argument, an emoji or other character sequence, with
+// its name from emoji-test.txt or NamedSequences.txt, if any, and the role
+// and name of each of its code points.
+func explain(args []string) {
+	loadEmojiTest()
+	loadNamedSequences()
+	for _, a := range args {
+		fmt.Printf("%s %s\n", a, sequenceName(a))
+		for _, r := range a {
+			fmt.Printf("\t%#U %s: %s\n", r, ucd.Name(r), emojiRole(r))
+		}
+	}
+}
+
+// sequenceName returns the name and status of the emoji or named sequence s,
+// or a note that it has none.
+func sequenceName(s string) string {
+	bare := strings.ReplaceAll(s, "\uFE0F", "")
+	for _, e := range emojiList {
+		if e.text == s {
+			return fmt.Sprintf("%s (%s, %s)", e.name, e.version, e.status)
+		}
+	}
+	for _, e := range emojiList {
+		if strings.ReplaceAll(e.text, "\uFE0F", "") == bare && e.status == "fully-qualified" {
+			return fmt.Sprintf("%s (%s, not fully qualified; the RGI form is %+q)", e.name, e.version, e.text)
+		}
+	}
+	for _, seq := range namedSeqs {
+		if seq.text == s {
+			return seq.name + " (named sequence)"
+		}
+	}
+	return "(not a recognized sequence)"
+}
+
+// emojiRole describes the part r plays in an emoji sequence.
+func emojiRole(r rune) string {
+	switch {
+	case r == 0x200D:
+		return "joiner"
+	case r == 0xFE0E:
+		return "text presentation selector"
+	case r == 0xFE0F:
+		return "emoji presentation selector"
+	case r == 0x20E3:
+		return "keycap"
+	case isSkinTone(r):
+		return "skin tone modifier"
+	case 0x1F9B0 <= r && r <= 0x1F9B3:
+		return "hair style component"
+	case 0x1F1E6 <= r && r <= 0x1F1FF:
+		return "regional indicator " + string('A'+r-0x1F1E6)
+	case r == 0xE007F:
+		return "cancel tag"
+	case 0xE0020 <= r && r <= 0xE007E:
+		return fmt.Sprintf("tag %q", r-0xE0000)
+	case lookupProp("Emoji_Modifier_Base")(r):
+		return "emoji, takes a skin tone"
+	case lookupProp("Emoji")(r):
+		return "emoji"
+	}
+	return "character"
+}
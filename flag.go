@@ -0,0 +1,126 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	regionalA = 0x1F1E6 // REGIONAL INDICATOR SYMBOL LETTER A
+	tagBase   = 0xE0000 // Tag characters are ASCII offset by tagBase.
+	cancelTag = 0xE007F
+	blackFlag = 0x1F3F4
+)
+
+// flags converts each argument between a region code and a flag emoji.
+// A code such as NL becomes a pair of regional indicators, and a subdivision
+// code such as GB-SCT becomes a tag sequence. Flags are converted back.
+func flags(args []string) {
+	loadEmojiTest()
+	for _, a := range args {
+		switch {
+		case isRegionCode(a):
+			f := regionFlag(a)
+			fmt.Printf("%s %s\n", f, flagName(f))
+		case isSubdivisionCode(a):
+			f := subdivisionFlag(a)
+			fmt.Printf("%s %s\n", f, flagName(f))
+		default:
+			explainFlags(a)
+		}
+	}
+}
+
+func isRegionCode(s string) bool {
+	return len(s) == 2 && isASCIILetters(s)
+}
+
+func isSubdivisionCode(s string) bool {
+	i := strings.IndexByte(s, '-')
+	return i == 2 && isASCIILetters(s[:i]) && len(s) > 3 && len(s) <= 6
+}
+
+func isASCIILetters(s string) bool {
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func regionFlag(code string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(code) {
+		b.WriteRune(regionalA + c - 'A')
+	}
+	return b.String()
+}
+
+func subdivisionFlag(code string) string {
+	var b strings.Builder
+	b.WriteRune(blackFlag)
+	for _, c := range strings.ToLower(strings.Replace(code, "-", "", 1)) {
+		b.WriteRune(tagBase + c)
+	}
+	b.WriteRune(cancelTag)
+	return b.String()
+}
+
+// flagName returns the emoji name of flag, or a note that it is not a
+// recommended (RGI) flag and so is unlikely to be displayed.
+func flagName(flag string) string {
+	for _, e := range emojiList {
+		if e.text == flag {
+			return e.name
+		}
+	}
+	return "(not a recognized flag)"
+}
+
+// explainFlags reports the regional indicator pairs and tag sequences in s.
+func explainFlags(s string) {
+	runes := []rune(s)
+	found := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case isRegional(r) && i+1 < len(runes) && isRegional(runes[i+1]):
+			f := string(runes[i : i+2])
+			fmt.Printf("%s regional indicator pair %c%c: %s\n", f, 'A'+r-regionalA, 'A'+runes[i+1]-regionalA, flagName(f))
+			i++
+			found = true
+		case isRegional(r):
+			fmt.Printf("%s unpaired regional indicator %c\n", string(r), 'A'+r-regionalA)
+			found = true
+		case r == blackFlag && i+1 < len(runes) && isTag(runes[i+1]):
+			j := i + 1
+			var code []rune
+			for ; j < len(runes) && isTag(runes[j]); j++ {
+				code = append(code, runes[j]-tagBase)
+			}
+			if j < len(runes) && runes[j] == cancelTag {
+				j++
+			}
+			f := string(runes[i:j])
+			fmt.Printf("%s tag sequence %s: %s\n", f, strings.ToUpper(string(code)), flagName(f))
+			i = j - 1
+			found = true
+		}
+	}
+	if !found {
+		fmt.Printf("%s: not a region code or flag\n", s)
+	}
+}
+
+func isRegional(r rune) bool {
+	return regionalA <= r && r < regionalA+26
+}
+
+func isTag(r rune) bool {
+	return tagBase+0x20 <= r && r < cancelTag
+}
@@ -0,0 +1,174 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// A fontFace is a font of an installed font file, of which a collection
+// holds several, with the character map it covers.
+type fontFace struct {
+	file string
+	name string
+	f    *sfnt // Only the cmap is set.
+}
+
+// fontSuffixes are the suffixes of the files of TrueType and OpenType fonts
+// and collections.
+var fontSuffixes = map[string]bool{".ttf": true, ".otf": true, ".ttc": true, ".otc": true}
+
+// fontDirs returns the directories in which the system keeps fonts.
+func fontDirs() []string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/System/Library/Fonts", "/Library/Fonts", filepath.Join(home, "Library/Fonts")}
+	case "windows":
+		return []string{filepath.Join(os.Getenv("WINDIR"), "Fonts"), filepath.Join(os.Getenv("LOCALAPPDATA"), `Microsoft\Windows\Fonts`)}
+	}
+	data := os.Getenv("XDG_DATA_HOME")
+	if data == "" {
+		data = filepath.Join(home, ".local/share")
+	}
+	return []string{"/usr/share/fonts", "/usr/local/share/fonts", filepath.Join(data, "fonts"), filepath.Join(home, ".fonts")}
+}
+
+// fontFiles returns the installed font files: those fontconfig knows, if
+// fc-list runs, or else those in the font directories of the system.
+func fontFiles() []string {
+	seen := make(map[string]bool)
+	if out, err := exec.Command("fc-list", "--format", "%{file}\n").Output(); err == nil {
+		for _, file := range strings.Split(string(out), "\n") {
+			if fontSuffixes[strings.ToLower(filepath.Ext(file))] {
+				seen[file] = true
+			}
+		}
+	}
+	if len(seen) == 0 {
+		for _, dir := range fontDirs() {
+			filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() && fontSuffixes[strings.ToLower(filepath.Ext(path))] {
+					seen[path] = true
+				}
+				return nil
+			})
+		}
+	}
+	var files []string
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// readFaces returns the faces of the font file: one, or each font of a
+// collection. Files that are not fonts, or have no Unicode character map,
+// have none.
+func readFaces(file string) []fontFace {
+	data, err := os.ReadFile(file)
+	if err != nil || len(data) < 12 {
+		return nil
+	}
+	bases := []int{0}
+	if string(data[:4]) == "ttcf" {
+		bases = nil
+		for i := 0; i < u32(data, 8); i++ {
+			bases = append(bases, u32(data, 12+4*i))
+		}
+	}
+	var faces []fontFace
+	for _, base := range bases {
+		tables, err := tableDirectory(data, base)
+		if err != nil {
+			continue
+		}
+		cmap := chooseCmap(tables["cmap"])
+		if cmap == nil {
+			continue
+		}
+		name := fontName(tables["name"])
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		faces = append(faces, fontFace{file, name, &sfnt{cmap: cmap}})
+	}
+	return faces
+}
+
+// fontName returns the full name of a font from its name table, preferring
+// the Windows name in US English, or "".
+func fontName(table []byte) string {
+	best, bestRank := "", 0
+	storage := u16(table, 4)
+	for i := 0; i < u16(table, 2); i++ {
+		rec := 6 + 12*i
+		platform, lang, id := u16(table, rec), u16(table, rec+4), u16(table, rec+6)
+		length, off := u16(table, rec+8), storage+u16(table, rec+10)
+		if id != 4 || off+length > len(table) {
+			continue
+		}
+		b := table[off : off+length]
+		rank, name := 0, ""
+		switch platform {
+		case 3:
+			rank = 2
+			if lang == 0x409 {
+				rank = 3
+			}
+			u := make([]uint16, len(b)/2)
+			for j := range u {
+				u[j] = uint16(u16(b, 2*j))
+			}
+			name = string(utf16.Decode(u))
+		case 1:
+			rank = 1
+			for _, c := range b {
+				name += string(rune(c))
+			}
+		}
+		if rank > bestRank {
+			best, bestRank = name, rank
+		}
+	}
+	return best
+}
+
+// outputFonts prints, for each of the codes, the installed fonts that have
+// a glyph for it, by name and file.
+func outputFonts(codes []rune) {
+	var faces []fontFace
+	for _, file := range fontFiles() {
+		faces = append(faces, readFaces(file)...)
+	}
+	if len(faces) == 0 {
+		fatalf("-fonts: no fonts found in %s", strings.Join(fontDirs(), ", "))
+	}
+	w := stdout
+	defer w.Flush()
+	for _, r := range codes {
+		fmt.Fprintf(w, "%U '%s' %s\n", r, glyph(r), strings.ToLower(ucd.Name(r)))
+		found := false
+		for _, face := range faces {
+			if face.f.glyphIndex(r) != 0 {
+				fmt.Fprintf(w, "\t%s\t%s\n", face.name, face.file)
+				found = true
+			}
+		}
+		if !found {
+			fmt.Fprintf(w, "\tno installed font has a glyph for it\n")
+		}
+	}
+}
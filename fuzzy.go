@@ -0,0 +1,162 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"sort"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// maxFuzzy is the number of characters a fuzzy search returns.
+const maxFuzzy = 20
+
+// A fuzzyMatch is a character matched by a fuzzy search, and its cost:
+// the total edit distance of the query words from the words of its name,
+// and the number of words of its name left unmatched.
+type fuzzyMatch struct {
+	r            rune
+	dist, unused int
+}
+
+// argsAreFuzzy returns, for each argument, the characters whose names or
+// aliases best match its words. Every word of the query must be within a
+// small edit distance of a different word of the name, in any order.
+func argsAreFuzzy() []rune {
+	var codes []rune
+	for _, a := range flag.Args() {
+		query := strings.Fields(strings.ToLower(a))
+		if len(query) == 0 {
+			continue
+		}
+		var matches []fuzzyMatch
+		ucd.Each(func(e ucd.Entry) {
+			best := fuzzyMatch{r: e.Code, dist: -1}
+			for _, name := range searchNames(e) {
+				if m, ok := fuzzyCost(query, strings.Fields(strings.ToLower(name))); ok && (best.dist < 0 || m.less(best)) {
+					best.dist, best.unused = m.dist, m.unused
+				}
+			}
+			if best.dist >= 0 {
+				matches = append(matches, best)
+			}
+		})
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].less(matches[j]) })
+		for i := 0; i < len(matches) && i < maxFuzzy; i++ {
+			codes = append(codes, matches[i].r)
+		}
+	}
+	return codes
+}
+
+func (m fuzzyMatch) less(n fuzzyMatch) bool {
+	if m.dist != n.dist {
+		return m.dist < n.dist
+	}
+	return m.unused < n.unused
+}
+
+// searchNames returns the names by which e may be found: its name, unless that
+// is a label such as <control>, its Unicode 1.0 name and its aliases.
+func searchNames(e ucd.Entry) []string {
+	var list []string
+	if e.Name[0] != '<' {
+		list = append(list, e.Name)
+	}
+	if e.OldName != "" {
+		list = append(list, e.OldName)
+	}
+	for _, a := range ucd.Aliases(e.Code) {
+		list = append(list, a.Name)
+	}
+	return list
+}
+
+// fuzzyCost matches each word of query to the closest unused word of name
+// and reports the cost, or false if some word has no close enough match.
+func fuzzyCost(query, name []string) (fuzzyMatch, bool) {
+	var m fuzzyMatch
+	if len(query) > len(name) {
+		return m, false
+	}
+	used := make([]bool, len(name))
+	for _, q := range query {
+		best, dist := -1, maxTypos(q)+1
+		for i, w := range name {
+			if !used[i] {
+				if d := editDistance(q, w, dist); d < dist {
+					best, dist = i, d
+				}
+			}
+		}
+		if best < 0 {
+			return m, false
+		}
+		used[best] = true
+		m.dist += dist
+	}
+	m.unused = len(name) - len(query)
+	return m, true
+}
+
+// maxTypos returns the number of edits tolerated in the query word w.
+func maxTypos(w string) int {
+	switch n := len(w); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	}
+	return 2
+}
+
+// editDistance returns the edit distance between a and b, counting
+// insertions, deletions, substitutions and transpositions of adjacent
+// letters, or limit if it is at least limit.
+func editDistance(a, b string, limit int) int {
+	if d := len(a) - len(b); d >= limit || -d >= limit {
+		return limit
+	}
+	// Rows i-2, i-1 and i of the distance matrix.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		min := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < cur[j] {
+				cur[j] = prev2[j-2] + 1
+			}
+			if cur[j] < min {
+				min = cur[j]
+			}
+		}
+		if min >= limit {
+			return limit
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	if prev[len(b)] > limit {
+		return limit
+	}
+	return prev[len(b)]
+}
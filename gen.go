@@ -0,0 +1,95 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+
+// Gen fetches the data files embedded in the unicode command and its ucd
+// package, all of them of the version of Unicode given by ucd.Version, and
+// stores them compressed with gzip. It is run by go generate.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// files lists the embedded files, by the path of each relative to the
+// root of the module, and their URLs, in which {ucd} stands for the
+// directory of the Unicode Character Database of ucd.Version and {v}
+// for the version itself.
+var files = []struct{ path, url string }{
+	{"ucd/UnicodeData.txt", "{ucd}/UnicodeData.txt"},
+	{"ucd/NameAliases.txt", "{ucd}/NameAliases.txt"},
+	{"BidiBrackets.txt", "{ucd}/BidiBrackets.txt"},
+	{"BidiMirroring.txt", "{ucd}/BidiMirroring.txt"},
+	{"Blocks.txt", "{ucd}/Blocks.txt"},
+	{"CaseFolding.txt", "{ucd}/CaseFolding.txt"},
+	{"DerivedAge.txt", "{ucd}/DerivedAge.txt"},
+	{"DerivedNormalizationProps.txt", "{ucd}/DerivedNormalizationProps.txt"},
+	{"EastAsianWidth.txt", "{ucd}/EastAsianWidth.txt"},
+	{"LineBreak.txt", "{ucd}/LineBreak.txt"},
+	{"NamedSequences.txt", "{ucd}/NamedSequences.txt"},
+	{"PropertyValueAliases.txt", "{ucd}/PropertyValueAliases.txt"},
+	{"ScriptExtensions.txt", "{ucd}/ScriptExtensions.txt"},
+	{"SpecialCasing.txt", "{ucd}/SpecialCasing.txt"},
+	{"StandardizedVariants.txt", "{ucd}/StandardizedVariants.txt"},
+	{"WordBreakProperty.txt", "{ucd}/auxiliary/WordBreakProperty.txt"},
+	{"SentenceBreakProperty.txt", "{ucd}/auxiliary/SentenceBreakProperty.txt"},
+	{"emoji-data.txt", "{ucd}/emoji/emoji-data.txt"},
+	{"emoji-variation-sequences.txt", "{ucd}/emoji/emoji-variation-sequences.txt"},
+	{"emoji-test.txt", "https://www.unicode.org/Public/emoji/{emoji}/emoji-test.txt"},
+	{"allkeys.txt", "https://www.unicode.org/Public/UCA/{v}/allkeys.txt"},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gen: ")
+	emoji := ucd.Version[:strings.LastIndexByte(ucd.Version, '.')]
+	expand := strings.NewReplacer(
+		"{ucd}", "https://www.unicode.org/Public/"+ucd.Version+"/ucd",
+		"{v}", ucd.Version,
+		"{emoji}", emoji,
+	)
+	client := &http.Client{Timeout: 5 * time.Minute}
+	for _, f := range files {
+		url := expand.Replace(f.url)
+		data, err := fetch(client, url)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var b bytes.Buffer
+		z, _ := gzip.NewWriterLevel(&b, gzip.BestCompression)
+		z.Write(data)
+		if err := z.Close(); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.FromSlash(f.path)+".gz", b.Bytes(), 0o666); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s: %d bytes\n", url, len(data))
+	}
+}
+
+// fetch returns the contents of the URL.
+func fetch(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
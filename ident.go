@@ -0,0 +1,85 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// identCheck reports the index of the first rune of s that may not appear
+// at its position in an identifier, or -1 if s is a valid identifier.
+// The start and cont functions test the first and subsequent runes.
+func identCheck(s string, start, cont func(rune) bool) int {
+	for i, r := range []rune(s) {
+		if i == 0 && !start(r) || i > 0 && !cont(r) {
+			return i
+		}
+	}
+	if s == "" {
+		return 0
+	}
+	return -1
+}
+
+// isGoLetter and isGoDigit are the letters and digits of the Go
+// specification, which identifiers are made of.
+func isGoLetter(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isGoDigit(r rune) bool {
+	return unicode.IsDigit(r)
+}
+
+// ident prints, for each argument, whether it is a valid identifier by
+// UAX #31 (XID_Start followed by XID_Continue) and in Go, naming the first
+// offending character, then the identifier properties of each rune.
+func ident(args []string) {
+	for _, a := range args {
+		fmt.Printf("'%s'\n", a)
+		runes := []rune(a)
+		for _, c := range []struct {
+			kind        string
+			start, cont func(rune) bool
+		}{
+			{"UAX #31", isXIDStart, isXIDContinue},
+			{"Go", isGoLetter, func(r rune) bool { return isGoLetter(r) || isGoDigit(r) }},
+		} {
+			i := identCheck(a, c.start, c.cont)
+			switch {
+			case i < 0:
+				fmt.Printf("\t%s: valid identifier\n", c.kind)
+			case len(runes) == 0:
+				fmt.Printf("\t%s: invalid: empty\n", c.kind)
+			default:
+				r := runes[i]
+				fmt.Printf("\t%s: invalid at rune %d: %U %s\n", c.kind, i, r, ucd.Name(r))
+			}
+		}
+		for _, r := range runes {
+			var props []string
+			if isXIDStart(r) {
+				props = append(props, "XID_Start")
+			}
+			if isXIDContinue(r) {
+				props = append(props, "XID_Continue")
+			}
+			switch {
+			case isGoLetter(r):
+				props = append(props, "Go letter")
+			case isGoDigit(r):
+				props = append(props, "Go digit")
+			}
+			if len(props) == 0 {
+				props = append(props, "not allowed in identifiers")
+			}
+			fmt.Printf("\t%U %s: %s\n", r, ucd.Name(r), strings.Join(props, ", "))
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// The IDNA mapping table of UTS #46, IdnaMappingTable.txt in the idna
+// directory of the Unicode site, is read from ucdDir. Its fields are the
+// status of each range, its mapping, if any, and, in older versions,
+// whether it is excluded from IDNA2008.
+var idnaTable []rangeValue
+
+// loadIDNA loads the IDNA mapping table and reports whether it is there.
+func loadIDNA() bool {
+	if idnaTable == nil {
+		text := readUCD("IdnaMappingTable.txt")
+		if text == "" {
+			return false
+		}
+		idnaTable = parseRanges(text)
+	}
+	return true
+}
+
+// idnaStatus returns the UTS #46 status of r, such as valid or mapped, the
+// text it maps to, and whether it is excluded from IDNA2008 although valid.
+func idnaStatus(r rune) (status, mapping string, nv8 bool) {
+	f := lookupRange(idnaTable, r)
+	if f == nil {
+		return "disallowed", "", false
+	}
+	if len(f) > 1 {
+		for _, c := range strings.Fields(f[1]) {
+			mapping += string(parseRune(c))
+		}
+	}
+	return f[0], mapping, len(f) > 2 && strings.TrimSpace(f[2]) != ""
+}
+
+// describeIDNA returns the UTS #46 status of r for -U, or "" if the IDNA
+// mapping table has not been installed by -update.
+func describeIDNA(r rune) string {
+	if !loadIDNA() {
+		return ""
+	}
+	status, mapping, nv8 := idnaStatus(r)
+	target := "nothing"
+	if mapping != "" {
+		target = fmt.Sprintf("%s '%s'", codePoints(mapping), mapping)
+	}
+	switch status {
+	case "valid":
+		if nv8 {
+			return "valid, but not in IDNA2008"
+		}
+		return "valid"
+	case "mapped":
+		return "mapped to " + target
+	case "deviation":
+		return "deviation: valid, but mapped to " + target + " by transitional processing"
+	case "ignored":
+		return "ignored: removed from domain names"
+	case "disallowed_STD3_valid":
+		return "valid, but disallowed by the STD3 ASCII rules"
+	case "disallowed_STD3_mapped":
+		return "mapped to " + target + ", but disallowed by the STD3 ASCII rules"
+	}
+	return status
+}
+
+// idna prints, for each argument, a domain name, its forms with A-labels
+// (xn--) and U-labels after the processing of UTS #46 and the problems
+// found, such as disallowed characters.
+func idna(args []string) {
+	if !loadIDNA() {
+		fatalf("no IDNA data: IdnaMappingTable.txt not found in %s; get it with -update", ucdDir())
+	}
+	for _, a := range args {
+		labels, problems := idnaProcess(a)
+		ascii := make([]string, len(labels))
+		for i, l := range labels {
+			ascii[i] = l
+			if !isASCII(l) {
+				p, err := punyEncode(l)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("label %q: %s", l, err))
+				}
+				ascii[i] = "xn--" + p
+			}
+		}
+		problems = append(problems, checkDNSLength(ascii)...)
+		fmt.Printf("'%s'\n", a)
+		fmt.Printf("\tA-labels: %s\n", strings.Join(ascii, "."))
+		fmt.Printf("\tU-labels: %s\n", strings.Join(labels, "."))
+		for _, p := range problems {
+			fmt.Printf("\terror: %s\n", p)
+		}
+	}
+}
+
+// idnaProcess applies the processing of UTS #46 to the domain name, as
+// browsers do: nontransitional, so deviations such as ß are kept, and
+// without the STD3 ASCII rules. It maps the characters of the name,
+// normalizes it to NFC and splits it into labels, decoding the A-labels,
+// and returns the labels and the problems found.
+func idnaProcess(domain string) (labels, problems []string) {
+	var b strings.Builder
+	for _, r := range domain {
+		status, mapping, _ := idnaStatus(r)
+		switch status {
+		case "valid", "deviation", "disallowed_STD3_valid":
+			b.WriteRune(r)
+		case "mapped", "disallowed_STD3_mapped":
+			b.WriteString(mapping)
+		case "ignored":
+		default:
+			problems = append(problems, fmt.Sprintf("%#U %s is disallowed", r, strings.ToLower(ucd.Name(r))))
+			b.WriteRune(r)
+		}
+	}
+	labels = strings.Split(norm.NFC.String(b.String()), ".")
+	for i, l := range labels {
+		if strings.HasPrefix(l, "xn--") {
+			u, err := punyDecode(l[len("xn--"):])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("label %q: %s", l, err))
+				continue
+			}
+			labels[i] = u
+			if !norm.NFC.IsNormalString(u) {
+				problems = append(problems, fmt.Sprintf("label %q: decodes to %q, which is not in NFC", l, u))
+			}
+			for _, r := range u {
+				switch status, _, _ := idnaStatus(r); status {
+				case "valid", "deviation", "disallowed_STD3_valid":
+				default:
+					problems = append(problems, fmt.Sprintf("label %q: %#U %s is %s", l, r, strings.ToLower(ucd.Name(r)), status))
+				}
+			}
+		}
+		problems = append(problems, checkLabel(labels[i])...)
+	}
+	return labels, problems
+}
+
+// checkLabel returns the problems of the hyphens and marks of a U-label.
+func checkLabel(l string) []string {
+	var problems []string
+	if len(l) >= 4 && l[2:4] == "--" {
+		problems = append(problems, fmt.Sprintf("label %q has hyphens in its third and fourth positions", l))
+	}
+	if strings.HasPrefix(l, "-") || strings.HasSuffix(l, "-") {
+		problems = append(problems, fmt.Sprintf("label %q begins or ends with a hyphen", l))
+	}
+	for _, r := range l {
+		if unicode.IsMark(r) {
+			problems = append(problems, fmt.Sprintf("label %q begins with the combining mark %#U", l, r))
+		}
+		break
+	}
+	return problems
+}
+
+// checkDNSLength returns the problems of the lengths of the A-labels of a
+// domain name: each must hold 1 to 63 bytes, except for an empty last
+// label, the root, and the name at most 253.
+func checkDNSLength(labels []string) []string {
+	var problems []string
+	for i, l := range labels {
+		switch {
+		case l == "" && i > 0 && i == len(labels)-1:
+		case l == "":
+			problems = append(problems, "empty label")
+		case len(l) > 63:
+			problems = append(problems, fmt.Sprintf("label %q is longer than 63 bytes", l))
+		}
+	}
+	if name := strings.TrimSuffix(strings.Join(labels, "."), "."); len(name) > 253 {
+		problems = append(problems, fmt.Sprintf("name is %d bytes, longer than 253", len(name)))
+	}
+	return problems
+}
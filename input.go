@@ -0,0 +1,108 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// An inputEncoding is an encoding that decodeInput recognizes, by its byte
+// order mark if it has one.
+type inputEncoding struct {
+	name string
+	bom  []byte
+	enc  encoding.Encoding
+}
+
+// UTF-32LE precedes UTF-16LE, whose mark begins its own.
+var inputEncodings = []inputEncoding{
+	{"UTF-8", []byte{0xEF, 0xBB, 0xBF}, unicode.UTF8},
+	{"UTF-32LE", []byte{0xFF, 0xFE, 0, 0}, utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM)},
+	{"UTF-32BE", []byte{0, 0, 0xFE, 0xFF}, utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)},
+	{"UTF-16LE", []byte{0xFF, 0xFE}, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	{"UTF-16BE", []byte{0xFE, 0xFF}, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+}
+
+// decodeInput returns data, read from standard input, as UTF-8. The
+// encoding is that of its byte order mark, if any, or else UTF-32 or UTF-16
+// if its zero bytes fall as they would for mostly ASCII text in those
+// encodings, or UTF-8 if data is valid UTF-8, or else Latin-1. Any encoding
+// but UTF-8 with no mark is reported on standard error.
+func decodeInput(data []byte) string {
+	name, enc, how := "", encoding.Encoding(nil), "by its byte order mark"
+	for _, e := range inputEncodings {
+		if bytes.HasPrefix(data, e.bom) {
+			name, enc = e.name, e.enc
+			data = data[len(e.bom):]
+			break
+		}
+	}
+	if name == "UTF-8" {
+		checkUTF8("input", string(data))
+	}
+	if enc == nil {
+		// ASCII in UTF-16 or UTF-32 is valid UTF-8, so look at zeros first.
+		name, how = guessEncoding(data), "by guess"
+		if name == "" && utf8.Valid(data) {
+			return string(data)
+		}
+		for _, e := range inputEncodings {
+			if e.name == name {
+				enc = e.enc
+			}
+		}
+		if enc == nil {
+			checkUTF8("input", string(data))
+			name, enc = "ISO-8859-1", charmap.ISO8859_1
+		}
+	}
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		fatalf("decoding input as %s: %s", name, err)
+	}
+	fmt.Fprintf(os.Stderr, "unicode: input is %s, %s\n", name, how)
+	return string(text)
+}
+
+// guessEncoding returns the name of the encoding of data, which has no byte
+// order mark: UTF-32 or UTF-16 of either order if at least half of its
+// units have zeros where ASCII characters would, or else "".
+func guessEncoding(data []byte) string {
+	zeros := func(size int, at ...int) bool {
+		if len(data) < size || len(data)%size != 0 {
+			return false
+		}
+		n := 0
+		for i := 0; i < len(data); i += size {
+			all := true
+			for _, j := range at {
+				all = all && data[i+j] == 0
+			}
+			if all {
+				n++
+			}
+		}
+		return 2*n >= len(data)/size
+	}
+	switch {
+	case zeros(4, 1, 2, 3):
+		return "UTF-32LE"
+	case zeros(4, 0, 1, 2):
+		return "UTF-32BE"
+	case zeros(2, 1):
+		return "UTF-16LE"
+	case zeros(2, 0):
+		return "UTF-16BE"
+	}
+	return ""
+}
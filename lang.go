@@ -0,0 +1,124 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// cldrURL is the root of the published CLDR data files.
+var cldrURL = "https://raw.githubusercontent.com/unicode-org/cldr/main/common/"
+
+// A localName is the CLDR name and keywords of a character or emoji
+// sequence in the language of -lang.
+type localName struct {
+	name     string
+	keywords string
+}
+
+// localNames maps a character or emoji sequence, without variation
+// selectors, to its localName. It is nil until loadLocalNames is called.
+var localNames map[string]localName
+
+var (
+	langRE            = regexp.MustCompile(`^[a-z]{2,3}(_[A-Za-z0-9]+)*$`)
+	localAnnotationRE = regexp.MustCompile(`<annotation cp="([^"]+)"( type="tts")?>([^<]*)</annotation>`)
+)
+
+// loadLocalNames reads the CLDR annotations, and the derived annotations
+// of emoji sequences, for the language, such as de or pt_BR, and then for
+// its parents, pt for pt_BR, to fill in what it inherits. The files are
+// downloaded into ucdDir on first use.
+func loadLocalNames(lang string) {
+	if localNames != nil {
+		return
+	}
+	lang = strings.ReplaceAll(lang, "-", "_")
+	if !langRE.MatchString(lang) {
+		fatalf("-lang: bad language %q; want a code such as de or pt_BR", lang)
+	}
+	localNames = make(map[string]localName)
+	for l := lang; l != ""; {
+		for _, dir := range []string{"annotations", "annotationsDerived"} {
+			text, err := cldrFile(dir + "/" + l + ".xml")
+			if err != nil {
+				if l == lang && dir == "annotations" {
+					fatalf("-lang: no CLDR annotations for %q: %s", lang, err)
+				}
+				continue
+			}
+			for _, m := range localAnnotationRE.FindAllStringSubmatch(text, -1) {
+				cp, value := html.UnescapeString(m[1]), html.UnescapeString(m[3])
+				if value == "↑↑↑" { // Inherited from the parent.
+					continue
+				}
+				n := localNames[cp]
+				switch {
+				case m[2] != "" && n.name == "":
+					n.name = value
+				case m[2] == "" && n.keywords == "":
+					n.keywords = strings.ReplaceAll(value, " | ", ", ")
+				}
+				localNames[cp] = n
+			}
+		}
+		if i := strings.LastIndexByte(l, '_'); i >= 0 {
+			l = l[:i]
+		} else {
+			l = ""
+		}
+	}
+}
+
+// cldrFile returns the named CLDR file from ucdDir, downloading it there
+// if it is not present.
+func cldrFile(name string) (string, error) {
+	if text := readUCD(name); text != "" {
+		return text, nil
+	}
+	data, err := fetch(cldrURL + name)
+	if err != nil {
+		return "", err
+	}
+	if dir := ucdDir(); dir != "" {
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(name)), data); err != nil {
+			fatalf("-lang: %s", err)
+		}
+	}
+	return string(data), nil
+}
+
+// localDescription returns the name and keywords of s in the language of
+// -lang, such as "de: Grinsendes Gesicht (Gesicht, lol)", or "" if there
+// is no -lang or CLDR does not name s.
+func localDescription(s string) string {
+	if *doLang == "" {
+		return ""
+	}
+	loadLocalNames(*doLang)
+	n, ok := localNames[strings.ReplaceAll(s, "\uFE0F", "")]
+	if !ok {
+		return ""
+	}
+	desc := *doLang + ":"
+	if n.name != "" {
+		desc += " " + n.name
+	}
+	if n.keywords != "" {
+		desc += " (" + n.keywords + ")"
+	}
+	return desc
+}
+
+// localSuffix returns the localDescription of s after a separator, or "".
+func localSuffix(s string) string {
+	if l := localDescription(s); l != "" {
+		return "; " + l
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+var (
+	//go:embed LineBreak.txt.gz
+	lineBreakGz []byte
+	lineBreaks  []rangeValue
+)
+
+// lineBreak returns the Line_Break property of r. Unlisted code points
+// are XX (Unknown).
+func lineBreak(r rune) string {
+	if lineBreaks == nil {
+		lineBreaks = parseRanges(ucdText("LineBreak.txt", lineBreakGz))
+	}
+	if f := lookupRange(lineBreaks, r); f != nil {
+		return f[0]
+	}
+	return "XX"
+}
+
+// lineBreakOpportunities prints each argument, or each line of standard
+// input if there are none, with the break opportunities of UAX #14 marked
+// by ÷, and mandatory breaks by !. Then it prints each line segment with
+// the byte offset at which it starts and the Line_Break class of its runes.
+func lineBreakOpportunities(args []string) {
+	for _, a := range argsOrStdin(args) {
+		type segment struct {
+			text      string
+			pos       int
+			mustBreak bool
+		}
+		var segs []segment
+		state := -1
+		for pos, rest := 0, a; rest != ""; {
+			var s segment
+			s.pos = pos
+			s.text, rest, s.mustBreak, state = uniseg.FirstLineSegmentInString(rest, state)
+			segs = append(segs, s)
+			pos += len(s.text)
+		}
+		var b strings.Builder
+		for i, s := range segs {
+			b.WriteString(s.text)
+			switch {
+			case i == len(segs)-1:
+				// The end of the text is always a break.
+			case s.mustBreak:
+				b.WriteString("!")
+			default:
+				b.WriteString("÷")
+			}
+		}
+		fmt.Printf("'%s'\n\t%s\n", a, b.String())
+		for _, s := range segs {
+			fmt.Printf("%d\t'%s'\n", s.pos, s.text)
+			for _, r := range s.text {
+				fmt.Printf("\t%U '%c' %s\n", r, r, lineBreak(r))
+			}
+		}
+	}
+}
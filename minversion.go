@@ -0,0 +1,66 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// minVersion prints the oldest version of Unicode that assigns every
+// character of the arguments, or of the lines of standard input if there
+// are none, followed by the characters of that version, which set the
+// floor, with the number of times each occurs. Unassigned characters,
+// which no version yet covers, are listed too.
+func minVersion(args []string) {
+	counts := make(map[rune]int)
+	for _, a := range argsOrStdin(args) {
+		for i := 0; i < len(a); {
+			r, size := utf8.DecodeRuneInString(a[i:])
+			i += size
+			if r == utf8.RuneError && size == 1 {
+				continue
+			}
+			counts[r]++
+		}
+	}
+	if len(counts) == 0 {
+		fatalf("-minversion: no characters")
+	}
+	newest := ""
+	var floor, unassigned []rune
+	for r := range counts {
+		a := age(r)
+		switch {
+		case a == "":
+			unassigned = append(unassigned, r)
+		case newest == "" || version(a) > version(newest):
+			newest, floor = a, []rune{r}
+		case a == newest:
+			floor = append(floor, r)
+		}
+	}
+	if newest != "" {
+		fmt.Printf("Unicode %s or later\n", newest)
+		printAged(floor, counts)
+	}
+	if len(unassigned) > 0 {
+		fmt.Printf("unassigned in every version:\n")
+		printAged(unassigned, counts)
+	}
+}
+
+// printAged prints each of the runes, in order, with its glyph, the number
+// of times it occurs in counts, and its name.
+func printAged(runes []rune, counts map[rune]int) {
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	for _, r := range runes {
+		fmt.Printf("\t%U '%s' %6d %s\n", r, glyph(r), counts[r], strings.ToLower(ucd.Name(r)))
+	}
+}
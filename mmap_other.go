@@ -0,0 +1,16 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package main
+
+import "os"
+
+// mapFile returns the contents of the named file. Memory mapping is not
+// supported on this system, so it reads the file.
+func mapFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
@@ -0,0 +1,76 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// namesList maps a rune to the annotations of the code charts in
+// NamesList.txt: informative aliases, notes, cross references and
+// variation sequences. It is nil until loadNamesList is called.
+var namesList map[rune][]string
+
+func loadNamesList() {
+	if namesList != nil {
+		return
+	}
+	namesList = make(map[rune][]string)
+	r := rune(-1)
+	for _, line := range splitLines(readUCD("NamesList.txt")) {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" || line[0] == ';' || line[0] == '@' {
+			continue
+		}
+		if line[0] != '\t' {
+			// A character line: code point, tab, name.
+			if tab := strings.IndexByte(line, '\t'); tab > 0 {
+				r = parseRune(line[:tab])
+			} else {
+				r = -1
+			}
+			continue
+		}
+		if r < 0 || len(line) < 3 {
+			continue
+		}
+		text := line[3:]
+		switch line[1] {
+		case '=':
+			namesList[r] = append(namesList[r], "= "+text)
+		case '*':
+			namesList[r] = append(namesList[r], "• "+text)
+		case 'x':
+			namesList[r] = append(namesList[r], "→ "+crossRef(text))
+		case '~':
+			namesList[r] = append(namesList[r], "~ "+text)
+		}
+	}
+}
+
+var crossRefRE = regexp.MustCompile(`^\((.*) - ([0-9A-F]{4,6})\)$`)
+
+// crossRef formats a cross reference, which is either a bare code point
+// or a parenthesized name and code point, as the code point and its name.
+func crossRef(text string) string {
+	if m := crossRefRE.FindStringSubmatch(text); m != nil {
+		return fmt.Sprintf("%#U %s", parseRune(m[2]), m[1])
+	}
+	if strings.Trim(text, "0123456789ABCDEF") != "" {
+		return text
+	}
+	r := parseRune(text)
+	return fmt.Sprintf("%#U %s", r, strings.ToLower(ucd.Name(r)))
+}
+
+// annotations returns the NamesList.txt annotations of r.
+func annotations(r rune) []string {
+	loadNamesList()
+	return namesList[r]
+}
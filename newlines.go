@@ -0,0 +1,100 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// lineTerminators names the characters that end lines in Unicode, the
+// mandatory breaks of UAX #14, by their abbreviations. CR followed by LF
+// is the single terminator CRLF.
+var lineTerminators = map[rune]string{
+	'\n':   "LF",
+	'\r':   "CR",
+	'\v':   "VT",
+	'\f':   "FF",
+	0x85:   "NEL",
+	0x2028: "LS",
+	0x2029: "PS",
+}
+
+// A terminator is a line terminator found in text, at a line and column
+// counted in characters.
+type terminator struct {
+	kind      string
+	r         rune
+	line, col int
+}
+
+// newlines prints, for the text of each named file, or of standard input
+// if there are none, the count of each kind of line terminator it holds
+// and, if it mixes them, which is the most common. Then it lists where
+// each of the others is, as file:line:column, along with every terminator
+// other than LF and CRLF, such as the LS and PS that JavaScript and JSON
+// take differently.
+func newlines(files []string) {
+	eachInput(files, func(i int, name string, data []byte) {
+		if i > 0 {
+			fmt.Println()
+		}
+		newlinesText(name, inputText(data))
+	})
+}
+
+// newlinesText prints the report of newlines for the text.
+func newlinesText(name, text string) {
+	var terms []terminator
+	tally := make(map[string]int)
+	line, col := 1, 1
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		kind, ok := lineTerminators[r]
+		if !ok {
+			col++
+			continue
+		}
+		if r == '\r' && i < len(text) && text[i] == '\n' {
+			kind = "CRLF"
+			i++
+		}
+		terms = append(terms, terminator{kind, r, line, col})
+		tally[kind]++
+		line, col = line+1, 1
+	}
+	lines := len(terms)
+	if col > 1 {
+		lines++
+	}
+	fmt.Printf("%s: %d lines, %d line terminators\n", name, lines, len(terms))
+	if col > 1 && len(terms) > 0 {
+		fmt.Printf("\tno terminator ends the last line\n")
+	}
+	printTally("line terminators", tally, len(terms))
+	common := ""
+	for kind, n := range tally {
+		if n > tally[common] || n == tally[common] && kind < common {
+			common = kind
+		}
+	}
+	if len(tally) > 1 {
+		fmt.Printf("mixed: the most common is %s\n", common)
+	}
+	for _, t := range terms {
+		if t.kind == common && (t.kind == "LF" || t.kind == "CRLF") {
+			continue
+		}
+		desc := t.kind
+		if t.kind != "CRLF" {
+			desc = fmt.Sprintf("%s %U %s", t.kind, t.r, strings.ToLower(ucd.Name(t.r)))
+		}
+		fmt.Printf("\t%s:%d:%d: %s\n", name, t.line, t.col, desc)
+	}
+}
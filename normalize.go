@@ -0,0 +1,63 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizing reports whether a normalization form was requested.
+func normalizing() bool {
+	return *doNFC || *doNFD || *doNFKC || *doNFKD
+}
+
+// normalize prints each argument, or the lines of standard input if there
+// are none, with its code points, followed by each requested normalization
+// form of it and its code points.
+func normalize(args []string) {
+	forms := []struct {
+		name string
+		do   *bool
+		form norm.Form
+	}{
+		{"NFC", doNFC, norm.NFC},
+		{"NFD", doNFD, norm.NFD},
+		{"NFKC", doNFKC, norm.NFKC},
+		{"NFKD", doNFKD, norm.NFKD},
+	}
+	for _, a := range argsOrStdin(args) {
+		fmt.Printf("input\t'%s'\t%s\n", a, codePoints(a))
+		for _, f := range forms {
+			if !*f.do {
+				continue
+			}
+			n := f.form.String(a)
+			note := ""
+			if n == a {
+				note = "\t(unchanged)"
+			}
+			fmt.Printf("%s\t'%s'\t%s%s\n", f.name, n, codePoints(n), note)
+		}
+	}
+}
+
+// argsOrStdin returns args, or if there are none the lines of standard input.
+func argsOrStdin(args []string) []string {
+	if len(args) > 0 {
+		for _, a := range args {
+			checkUTF8(fmt.Sprintf("argument %q", a), a)
+		}
+		return args
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	return splitLines(decodeInput(data))
+}
@@ -0,0 +1,71 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+var (
+	//go:embed DerivedNormalizationProps.txt.gz
+	normPropsGz []byte
+	normProps   map[string][]rangeValue
+)
+
+// quickChecks lists the normalization quick check properties.
+var quickChecks = []string{"NFD_QC", "NFC_QC", "NFKD_QC", "NFKC_QC"}
+
+func init() {
+	for _, name := range []string{
+		"Full_Composition_Exclusion",
+		"Changes_When_NFKC_Casefolded",
+	} {
+		name := name
+		addProp(name, func(r rune) bool { return normProp(name, r) != nil })
+	}
+}
+
+// normProp returns the fields of the entry for r of the named property
+// of DerivedNormalizationProps.txt, or nil if there is none.
+func normProp(name string, r rune) []string {
+	if normProps == nil {
+		normProps = make(map[string][]rangeValue)
+		for _, v := range parseRanges(ucdText("DerivedNormalizationProps.txt", normPropsGz)) {
+			normProps[v.fields[0]] = append(normProps[v.fields[0]], v)
+		}
+	}
+	return lookupRange(normProps[name], r)
+}
+
+// quickCheck returns the quick check values of r that are not Yes,
+// in the form NFC_QC=M.
+func quickCheck(r rune) []string {
+	var list []string
+	for _, qc := range quickChecks {
+		if f := normProp(qc, r); f != nil {
+			list = append(list, qc+"="+f[1])
+		}
+	}
+	return list
+}
+
+// nfkcCasefold returns the NFKC_Casefold mapping of r in U+ notation and
+// whether r has one. A mapping to nothing is reported as "(removed)".
+func nfkcCasefold(r rune) (string, bool) {
+	f := normProp("NFKC_CF", r)
+	if f == nil {
+		return "", false
+	}
+	if f[1] == "" {
+		return "(removed)", true
+	}
+	var codes []string
+	for _, c := range strings.Fields(f[1]) {
+		codes = append(codes, fmt.Sprintf("U+%s", c))
+	}
+	return strings.Join(codes, " "), true
+}
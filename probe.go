@@ -0,0 +1,187 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/uniseg"
+)
+
+// probeTimeout is how long -probe waits for the terminal to report the
+// position of its cursor.
+const probeTimeout = 2 * time.Second
+
+// probeCases returns the strings -probe measures by default: characters
+// whose width terminals are known to disagree on.
+func probeCases() []tortureCase {
+	cases := []tortureCase{
+		{"ASCII", "abc"},
+		{"wide", "漢字"},
+		{"fullwidth", "ＡＢ"},
+		{"halfwidth", "ｶﾀ"},
+		{"ambiguous", "±①Ω"},
+		{"combining mark", "é"},
+		{"Hangul jamo", "각"},
+		{"Devanagari conjunct", "क्षि"},
+		{"Thai with marks", "น้ำ"},
+		{"zero width space", "a​b"},
+		{"soft hyphen", "a­b"},
+		{"emoji", "😀"},
+		{"text-default emoji", "☺"},
+		{"emoji presentation selector", "☺️"},
+		{"text presentation selector", "⌚︎"},
+		{"skin tone modifier", "\U0001F44D\U0001F3FD"},
+		{"ZWJ sequence", "\U0001F468‍\U0001F469‍\U0001F467"},
+		{"flag", "\U0001F1EF\U0001F1F5"},
+		{"keycap", "1️⃣"},
+	}
+	// The newest emoji, which older terminals and fonts do not know.
+	loadEmojiTest()
+	var newest emoji
+	for _, e := range emojiList {
+		if e.status == "fully-qualified" && (newest.version == "" || version(e.version[1:]) > version(newest.version[1:])) {
+			newest = e
+		}
+	}
+	if newest.text != "" {
+		cases = append(cases, tortureCase{"newest emoji, " + newest.version, newest.text})
+	}
+	return cases
+}
+
+// probe prints each argument, or each line of standard input, or if there
+// are none each of probeCases, to the terminal, asks the terminal where its
+// cursor went, and compares the number of cells it advanced with the width
+// computed as by -width. The report, with each mismatch marked, follows.
+func probe(args []string) {
+	if runtime.GOOS == "windows" {
+		fatalf("-probe needs a terminal that reports the cursor position; not supported on Windows")
+	}
+	var cases []tortureCase
+	if len(args) > 0 || !isTerminal(os.Stdin) {
+		for _, a := range argsOrStdin(args) {
+			cases = append(cases, tortureCase{"", a})
+		}
+	} else {
+		cases = probeCases()
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		fatalf("-probe needs a terminal: %s", err)
+	}
+	defer tty.Close()
+	widths, err := measure(tty, cases)
+	if err != nil {
+		fatalf("-probe: %s", err)
+	}
+	term := os.Getenv("TERM_PROGRAM")
+	if term == "" {
+		term = os.Getenv("TERM")
+	}
+	fmt.Printf("terminal: %s\n", term)
+	mismatches := 0
+	for i, c := range cases {
+		want := uniseg.StringWidth(c.text)
+		mark := ""
+		if widths[i] != want {
+			mark = "\tMISMATCH"
+			mismatches++
+		}
+		name := ""
+		if c.name != "" {
+			name = c.name + ": "
+		}
+		fmt.Printf("%s'%s'\t%s\twidth %d, terminal %d%s\n", name, c.text, codePoints(c.text), want, widths[i], mark)
+	}
+	fmt.Printf("%d of %d differ\n", mismatches, len(cases))
+}
+
+// isTerminal reports whether f is a character device, such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// stty runs stty with the arguments on the terminal and returns its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// measure prints each case at the start of a line of the terminal, in raw
+// mode, and returns the columns the cursor advanced over for each.
+func measure(tty *os.File, cases []tortureCase) ([]int, error) {
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("stty: %s", err)
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("stty: %s", err)
+	}
+	defer stty(tty, saved)
+	replies := make(chan []byte)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := tty.Read(buf)
+			if err != nil {
+				close(replies)
+				return
+			}
+			replies <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+	var widths []int
+	for _, c := range cases {
+		fmt.Fprintf(tty, "\r\x1b[K%s\x1b[6n", c.text)
+		col, err := cursorColumn(replies)
+		if err != nil {
+			fmt.Fprintf(tty, "\r\x1b[K")
+			return nil, err
+		}
+		widths = append(widths, col-1)
+	}
+	fmt.Fprintf(tty, "\r\x1b[K")
+	return widths, nil
+}
+
+// cursorColumn returns the column of the cursor position report, ESC [
+// row ; column R, that the terminal sends.
+func cursorColumn(replies chan []byte) (int, error) {
+	var reply []byte
+	timeout := time.After(probeTimeout)
+	for {
+		select {
+		case b, ok := <-replies:
+			if !ok {
+				return 0, errors.New("terminal closed")
+			}
+			reply = append(reply, b...)
+		case <-timeout:
+			return 0, errors.New("the terminal does not report the cursor position")
+		}
+		i := bytes.Index(reply, []byte("\x1b["))
+		j := bytes.IndexByte(reply, 'R')
+		if i < 0 || j < i {
+			continue
+		}
+		pos := strings.Split(string(reply[i+2:j]), ";")
+		if len(pos) != 2 {
+			return 0, fmt.Errorf("bad cursor position report %q", reply[i:j+1])
+		}
+		return strconv.Atoi(pos[1])
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// binaryProps maps the loose form of a binary property name to its canonical
+// name and membership test. The tables of PropList.txt come from package
+// unicode, which is generated from that file.
+var binaryProps = make(map[string]binaryProp)
+
+type binaryProp struct {
+	name string
+	is   func(rune) bool
+}
+
+// propNames holds the canonical property names in alphabetical order.
+var propNames []string
+
+func init() {
+	for name, table := range unicode.Properties {
+		addProp(name, isIn(table))
+	}
+}
+
+func addProp(name string, is func(rune) bool) {
+	binaryProps[looseName(name)] = binaryProp{name, is}
+	propNames = append(propNames, name)
+	sort.Strings(propNames)
+}
+
+// isIn returns a membership test for the union of tables.
+func isIn(tables ...*unicode.RangeTable) func(rune) bool {
+	return func(r rune) bool {
+		return unicode.IsOneOf(tables, r)
+	}
+}
+
+// looseName returns the form of a property name used for matching,
+// ignoring case, spaces, hyphens and underscores as in UAX #44.
+func looseName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// lookupProp returns the membership test for the named binary property.
+func lookupProp(name string) func(rune) bool {
+	p, ok := binaryProps[looseName(name)]
+	if !ok {
+		fatalf("unknown property %q", name)
+	}
+	return p.is
+}
+
+// withProps returns the runes of codes that have any of the binary
+// properties in the comma-separated list props.
+func withProps(codes []rune, props string) []rune {
+	var tests []func(rune) bool
+	for _, p := range strings.Split(props, ",") {
+		tests = append(tests, lookupProp(p))
+	}
+	var out []rune
+	for _, r := range codes {
+		for _, is := range tests {
+			if is(r) {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// properties returns the names of the binary properties of r.
+func properties(r rune) []string {
+	var names []string
+	for _, name := range propNames {
+		if binaryProps[looseName(name)].is(r) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// outputProps prints each of codes followed by the properties it has, in
+// the \p{...} notation of -set and of regular expressions: the values of
+// its enumerated properties, including those of the XML database if it is
+// present, then its binary properties.
+func outputProps(codes []rune) {
+	defer stdout.Flush()
+	for _, r := range codes {
+		name := ucd.Name(r)
+		if !ucd.Lookup(r).Assigned() {
+			name = noEntry(r)
+		}
+		fmt.Fprintf(stdout, "%#U %s\n", r, name)
+		cat := category(r)
+		if cat == "" {
+			cat = "Cn"
+		}
+		props := []string{"gc=" + cat, cat[:1], "Script=" + script(r)}
+		for _, sc := range scriptExtensions(r) {
+			props = append(props, "scx="+sc)
+		}
+		props = append(props, "Block="+strings.ReplaceAll(block(r), " ", "_"))
+		if a := age(r); a != "" {
+			props = append(props, "Age="+a)
+		}
+		props = append(props,
+			fmt.Sprintf("ccc=%d", ucd.Lookup(r).CombiningClass),
+			"bc="+bidiClass(r),
+			"lb="+lineBreak(r),
+			"ea="+eastAsianWidth(r),
+			"wb="+wordBreak(r),
+			"sb="+sentenceBreak(r),
+		)
+		if ucdXMLSource() != "" {
+			for _, p := range xmlEnumProps {
+				if v := xmlProp(p.short, r); v != "" {
+					props = append(props, p.short+"="+v)
+				}
+			}
+		}
+		props = append(props, properties(r)...)
+		for _, p := range props {
+			fmt.Fprintf(stdout, "\t\\p{%s}\n", p)
+		}
+	}
+}
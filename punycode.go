@@ -0,0 +1,169 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// This file implements Punycode, RFC 3492, the encoding of the labels of
+// internationalized domain names in ASCII.
+
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+var errPunyOverflow = errors.New("punycode: overflow")
+
+// punyAdapt returns the bias after a delta.
+func punyAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
+
+// punyThreshold returns the threshold t of RFC 3492 for position k.
+func punyThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punyTMin
+	case k >= bias+punyTMax:
+		return punyTMax
+	}
+	return k - bias
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+// punyEncode returns the Punycode of s, without the xn-- prefix.
+func punyEncode(s string) (string, error) {
+	runes := []rune(s)
+	var b strings.Builder
+	for _, r := range runes {
+		if r < 0x80 {
+			b.WriteRune(r)
+		}
+	}
+	basic := b.Len()
+	h := basic
+	if h > 0 {
+		b.WriteByte('-')
+	}
+	n, delta, bias := punyInitialN, 0, punyInitialBias
+	for h < len(runes) {
+		m := int(^uint(0) >> 1)
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		if (m-n)*(h+1) < 0 || delta+(m-n)*(h+1) < delta {
+			return "", errPunyOverflow
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := punyBase; ; k += punyBase {
+				t := punyThreshold(k, bias)
+				if q < t {
+					break
+				}
+				b.WriteByte(punyDigit(t + (q-t)%(punyBase-t)))
+				q = (q - t) / (punyBase - t)
+			}
+			b.WriteByte(punyDigit(q))
+			bias = punyAdapt(delta, h+1, h == basic)
+			h++
+			delta = 0
+		}
+		delta++
+		n++
+	}
+	return b.String(), nil
+}
+
+// punyDecode returns the text encoded by the Punycode s, without the
+// xn-- prefix.
+func punyDecode(s string) (string, error) {
+	var out []rune
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		for _, c := range s[:i] {
+			if c >= 0x80 {
+				return "", errors.New("punycode: non-ASCII basic code point")
+			}
+			out = append(out, c)
+		}
+		s = s[i+1:]
+	}
+	n, i, bias := punyInitialN, 0, punyInitialBias
+	for p := 0; p < len(s); {
+		oldi, w := i, 1
+		for k := punyBase; ; k += punyBase {
+			if p >= len(s) {
+				return "", errors.New("punycode: truncated")
+			}
+			var d int
+			switch c := s[p]; {
+			case 'a' <= c && c <= 'z':
+				d = int(c - 'a')
+			case 'A' <= c && c <= 'Z':
+				d = int(c - 'A')
+			case '0' <= c && c <= '9':
+				d = int(c-'0') + 26
+			default:
+				return "", errors.New("punycode: bad digit")
+			}
+			p++
+			if d > (1<<31-1-i)/w {
+				return "", errPunyOverflow
+			}
+			i += d * w
+			t := punyThreshold(k, bias)
+			if d < t {
+				break
+			}
+			w *= punyBase - t
+		}
+		bias = punyAdapt(i-oldi, len(out)+1, oldi == 0)
+		n += i / (len(out) + 1)
+		i %= len(out) + 1
+		if n > 0x10FFFF {
+			return "", errPunyOverflow
+		}
+		out = append(out, 0)
+		copy(out[i+1:], out[i:])
+		out[i] = rune(n)
+		i++
+	}
+	return string(out), nil
+}
@@ -0,0 +1,313 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// A query is a Boolean expression over the properties of characters, such as
+//
+//	Script=Greek & gc=Lu & !Age>15.0
+//
+// Its operators are, by increasing precedence, | (union), & (intersection)
+// and ! (complement), with parentheses for grouping. Each operand is a
+// property, compared to a value by =, !=, <, <=, > or >=, or a bare name,
+// which is a binary property, a general category, a script, or Any,
+// Assigned or ASCII. The ordering
+// comparisons apply to the numeric properties Age and ccc.
+
+// A predicate reports whether a rune is in a set.
+type predicate func(r rune) bool
+
+// queryTokens splits a query into operators and words. A word may be quoted
+// to include spaces or operator characters.
+func queryTokens(q string) []string {
+	var toks []string
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(q[i:], "!=") || strings.HasPrefix(q[i:], "<=") || strings.HasPrefix(q[i:], ">="):
+			toks = append(toks, q[i:i+2])
+			i += 2
+		case strings.IndexByte("&|!()=<>", c) >= 0:
+			toks = append(toks, q[i:i+1])
+			i++
+		case c == '"' || c == '\'':
+			j := strings.IndexByte(q[i+1:], c)
+			if j < 0 {
+				fatalf("-q: unterminated quote in %q", q)
+			}
+			toks = append(toks, q[i+1:i+1+j])
+			i += j + 2
+		default:
+			j := i
+			for j < len(q) && strings.IndexByte("&|!()=<> \t\"'", q[j]) < 0 {
+				j++
+			}
+			toks = append(toks, q[i:j])
+			i = j
+		}
+	}
+	return toks
+}
+
+// A queryParser parses a query by recursive descent.
+type queryParser struct {
+	query string
+	toks  []string
+}
+
+func (p *queryParser) peek() string {
+	if len(p.toks) == 0 {
+		return ""
+	}
+	return p.toks[0]
+}
+
+func (p *queryParser) next() string {
+	t := p.peek()
+	if t == "" {
+		fatalf("-q: unexpected end of %q", p.query)
+	}
+	p.toks = p.toks[1:]
+	return t
+}
+
+// parseQuery returns the predicate for the query q.
+func parseQuery(q string) predicate {
+	p := &queryParser{query: q, toks: queryTokens(q)}
+	pred := p.union()
+	if len(p.toks) > 0 {
+		fatalf("-q: unexpected %q in %q", p.peek(), q)
+	}
+	return pred
+}
+
+func (p *queryParser) union() predicate {
+	pred := p.intersection()
+	for p.peek() == "|" {
+		p.next()
+		x, y := pred, p.intersection()
+		pred = func(r rune) bool { return x(r) || y(r) }
+	}
+	return pred
+}
+
+func (p *queryParser) intersection() predicate {
+	pred := p.unary()
+	for p.peek() == "&" {
+		p.next()
+		x, y := pred, p.unary()
+		pred = func(r rune) bool { return x(r) && y(r) }
+	}
+	return pred
+}
+
+func (p *queryParser) unary() predicate {
+	switch t := p.next(); t {
+	case "!":
+		x := p.unary()
+		return func(r rune) bool { return !x(r) }
+	case "(":
+		pred := p.union()
+		if p.next() != ")" {
+			fatalf("-q: missing ) in %q", p.query)
+		}
+		return pred
+	case "&", "|", ")", "=", "!=", "<", "<=", ">", ">=":
+		fatalf("-q: unexpected %q in %q", t, p.query)
+		return nil
+	default:
+		switch op := p.peek(); op {
+		case "=", "!=", "<", "<=", ">", ">=":
+			p.next()
+			return propertyPredicate(t, op, p.next())
+		}
+		return namePredicate(t)
+	}
+}
+
+// cmpFunc returns a test of the result of a comparison, such as
+// strings.Compare, for the operator op, or nil if op is not an operator.
+func cmpFunc(op string) func(c int) bool {
+	switch op {
+	case "", "=", "==":
+		return func(c int) bool { return c == 0 }
+	case "!=":
+		return func(c int) bool { return c != 0 }
+	case "<":
+		return func(c int) bool { return c < 0 }
+	case "<=":
+		return func(c int) bool { return c <= 0 }
+	case ">":
+		return func(c int) bool { return c > 0 }
+	case ">=":
+		return func(c int) bool { return c >= 0 }
+	}
+	return nil
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// namePredicate returns the predicate for a bare name: a binary property,
+// a general category, a script, or Any, Assigned or ASCII.
+func namePredicate(name string) predicate {
+	switch looseName(name) {
+	case "any":
+		return func(rune) bool { return true }
+	case "assigned":
+		return func(r rune) bool { return ucd.Lookup(r).Assigned() }
+	case "ascii":
+		return func(r rune) bool { return r < 0x80 }
+	}
+	if p, ok := binaryProps[looseName(name)]; ok {
+		return p.is
+	}
+	if unicode.Categories[name] != nil {
+		return categoryPredicate(name)
+	}
+	loadScriptAliases()
+	if sc, ok := scriptsByLooseName[looseName(name)]; ok {
+		return func(r rune) bool { return script(r) == sc }
+	}
+	fatalf("-q: unknown property, category or script %q", name)
+	return nil
+}
+
+func categoryPredicate(cat string) predicate {
+	if unicode.Categories[cat] == nil {
+		fatalf("unknown category %q", cat)
+	}
+	return func(r rune) bool {
+		c := category(r)
+		return c == cat || len(cat) == 1 && strings.HasPrefix(c, cat)
+	}
+}
+
+// propertyPredicate returns the predicate for the comparison of the named
+// property with value.
+func propertyPredicate(name, op, value string) predicate {
+	cmp := cmpFunc(op)
+	var is predicate
+	switch looseName(name) {
+	case "age":
+		v := version(value)
+		return func(r rune) bool {
+			a := age(r)
+			return a != "" && cmp(compareInts(version(a), v))
+		}
+	case "ccc", "canonicalcombiningclass":
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			v = -1
+			for n, name := range cccNames {
+				if looseName(name) == looseName(value) {
+					v = n
+				}
+			}
+			if v < 0 {
+				fatalf("-q: unknown combining class %q", value)
+			}
+		}
+		return func(r rune) bool {
+			e := ucd.Lookup(r)
+			return e.Assigned() && cmp(compareInts(int(e.CombiningClass), v))
+		}
+	case "gc", "generalcategory", "category":
+		is = categoryPredicate(value)
+	case "sc", "script":
+		sc := scriptName(value)
+		is = func(r rune) bool { return script(r) == sc }
+	case "scx", "scriptextensions":
+		sc := scriptName(value)
+		is = func(r rune) bool {
+			for _, name := range scriptExtensions(r) {
+				if name == sc {
+					return true
+				}
+			}
+			return false
+		}
+	case "blk", "block":
+		blk := lookupBlock(value)
+		is = func(r rune) bool { return blk.lo <= r && r <= blk.hi }
+	case "na", "name":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			fatalf("-q: %s", err)
+		}
+		is = func(r rune) bool { return ucd.Lookup(r).Match(re) }
+	case "bc", "bidiclass":
+		is = stringPredicate(bidiClass, value)
+	case "lb", "linebreak":
+		is = stringPredicate(lineBreak, value)
+	case "ea", "eastasianwidth":
+		is = stringPredicate(eastAsianWidth, value)
+	case "wb", "wordbreak":
+		is = stringPredicate(wordBreak, value)
+	case "sb", "sentencebreak":
+		is = stringPredicate(sentenceBreak, value)
+	default:
+		if short := xmlPropName(name); short != "" {
+			v := xmlValue(short, value)
+			is = func(r rune) bool { return strings.EqualFold(xmlProp(short, r), v) }
+			break
+		}
+		p, ok := binaryProps[looseName(name)]
+		if !ok {
+			fatalf("-q: unknown property %q", name)
+		}
+		switch strings.ToLower(value) {
+		case "y", "yes", "t", "true":
+			is = p.is
+		case "n", "no", "f", "false":
+			is = func(r rune) bool { return !p.is(r) }
+		default:
+			fatalf("-q: binary property %s needs the value Yes or No, not %q", name, value)
+		}
+	}
+	switch op {
+	case "=":
+		return is
+	case "!=":
+		return func(r rune) bool { return !is(r) }
+	}
+	fatalf("-q: %s compares only with = and !=", name)
+	return nil
+}
+
+// stringPredicate returns the predicate for the property given by fn having
+// the value, ignoring case.
+func stringPredicate(fn func(rune) string, value string) predicate {
+	return func(r rune) bool { return strings.EqualFold(fn(r), value) }
+}
+
+// matchingQuery returns the runes of codes that satisfy the query q.
+func matchingQuery(codes []rune, q string) []rune {
+	pred := parseQuery(q)
+	var out []rune
+	for _, r := range codes {
+		if pred(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// sample returns n of codes chosen at random, or all of them in random
+// order if there are fewer. Surrogates, which cannot be encoded, are
+// never chosen. A seed of 0 selects a different sample each time.
+func sample(codes []rune, n int, seed int64) []rune {
+	if n < 0 {
+		fatalf("-rand must not be negative")
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	var pool []rune
+	for _, r := range codes {
+		if r < 0xD800 || 0xDFFF < r {
+			pool = append(pool, r)
+		}
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	rnd.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	if n < len(pool) {
+		pool = pool[:n]
+	}
+	return pool
+}
+
+// inScriptsOnly returns the runes of codes whose Script property is one of
+// the comma-separated list scripts.
+func inScriptsOnly(codes []rune, scripts string) []rune {
+	want := make(map[string]bool)
+	for _, s := range strings.Split(scripts, ",") {
+		want[scriptName(s)] = true
+	}
+	var out []rune
+	for _, r := range codes {
+		if want[script(r)] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
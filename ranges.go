@@ -0,0 +1,120 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/rangetable"
+)
+
+// A runeRange is a run of consecutive code points, lo through hi.
+type runeRange struct {
+	lo, hi rune
+}
+
+// runs returns the runs of consecutive code points in codes, in order.
+func runs(codes []rune) []runeRange {
+	sorted := append([]rune(nil), codes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var list []runeRange
+	for _, r := range sorted {
+		if n := len(list); n > 0 && r <= list[n-1].hi+1 {
+			if r > list[n-1].hi {
+				list[n-1].hi = r
+			}
+			continue
+		}
+		list = append(list, runeRange{r, r})
+	}
+	return list
+}
+
+// outputRanges prints codes as runs of code points in hex, one per line,
+// in the form the arguments take, such as 0410-044f.
+func outputRanges(codes []rune) {
+	for _, rr := range runs(codes) {
+		if rr.lo == rr.hi {
+			fmt.Printf("%.4x\n", rr.lo)
+		} else {
+			fmt.Printf("%.4x-%.4x\n", rr.lo, rr.hi)
+		}
+	}
+}
+
+// outputGoTable prints codes as Go source declaring a *unicode.RangeTable
+// with the given name.
+func outputGoTable(name string, codes []rune) {
+	if !token.IsIdentifier(name) {
+		fatalf("-gotable: %q is not a Go identifier", name)
+	}
+	t := rangetable.New(append([]rune(nil), codes...)...) // New sorts its argument.
+	n := 0
+	rangetable.Visit(t, func(rune) { n++ })
+	fmt.Printf("// %s is the set of %d characters selected by unicode %s.\n", name, n, strings.Join(os.Args[1:], " "))
+	fmt.Printf("var %s = &unicode.RangeTable{\n", name)
+	if len(t.R16) > 0 {
+		fmt.Printf("\tR16: []unicode.Range16{\n")
+		for _, r := range t.R16 {
+			fmt.Printf("\t\t{0x%04x, 0x%04x, %d},\n", r.Lo, r.Hi, r.Stride)
+		}
+		fmt.Printf("\t},\n")
+	}
+	if len(t.R32) > 0 {
+		fmt.Printf("\tR32: []unicode.Range32{\n")
+		for _, r := range t.R32 {
+			fmt.Printf("\t\t{0x%x, 0x%x, %d},\n", r.Lo, r.Hi, r.Stride)
+		}
+		fmt.Printf("\t},\n")
+	}
+	if t.LatinOffset > 0 {
+		fmt.Printf("\tLatinOffset: %d,\n", t.LatinOffset)
+	}
+	fmt.Printf("}\n")
+}
+
+// outputClass prints codes as a regular expression character class in the
+// syntax of dialect: go (RE2), pcre, js (with the u flag) or python.
+func outputClass(dialect string, codes []rune) {
+	var escape func(r rune) string
+	switch dialect {
+	case "go", "re2", "pcre":
+		escape = func(r rune) string { return fmt.Sprintf(`\x{%X}`, r) }
+	case "js", "javascript":
+		escape = func(r rune) string { return fmt.Sprintf(`\u{%X}`, r) }
+	case "python":
+		escape = func(r rune) string {
+			if r > 0xFFFF {
+				return fmt.Sprintf(`\U%08X`, r)
+			}
+			return fmt.Sprintf(`\u%04X`, r)
+		}
+	default:
+		fatalf("-class: unknown dialect %q; want go, pcre, js or python", dialect)
+	}
+	char := func(r rune) string {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return string(r)
+		}
+		return escape(r)
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	for _, rr := range runs(codes) {
+		b.WriteString(char(rr.lo))
+		switch {
+		case rr.hi == rr.lo+1:
+			b.WriteString(char(rr.hi))
+		case rr.hi > rr.lo:
+			b.WriteString("-" + char(rr.hi))
+		}
+	}
+	b.WriteByte(']')
+	fmt.Println(b.String())
+}
@@ -0,0 +1,101 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// related prints, for each character of the arguments, the characters
+// related to it: its case mappings and the characters that map to it,
+// its decomposition and the characters whose decompositions contain it,
+// the cross references of NamesList.txt, the characters confusable with
+// it, and its variation sequences.
+func related(args []string) {
+	seen := make(map[rune]bool)
+	for _, a := range args {
+		for _, r := range a {
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			relations(r)
+		}
+	}
+}
+
+// relations prints the characters related to r, each once, with the
+// relation that first found it.
+func relations(r rune) {
+	fmt.Printf("%#U %s\n", r, strings.ToLower(ucd.Name(r)))
+	shown := map[rune]bool{r: true}
+	show := func(how string, c rune) {
+		if !shown[c] {
+			shown[c] = true
+			fmt.Printf("\t%s: %#U %s\n", how, c, strings.ToLower(ucd.Name(c)))
+		}
+	}
+	for _, kind := range []string{"upper", "lower", "title"} {
+		m := caseMapping(r, kind)
+		switch {
+		case m == string(r):
+		case len([]rune(m)) == 1:
+			show(kind+"case", []rune(m)[0])
+		default:
+			fmt.Printf("\t%scase: %s '%s'\n", kind, codePoints(m), m)
+		}
+	}
+	if f := fullFold(r); f != string(r) && len([]rune(f)) == 1 {
+		show("case folding", []rune(f)[0])
+	}
+	tag, parts := decomposition(r, strings.Split(lookup(r), ";"))
+	if tag == "" {
+		tag = "canonical"
+	}
+	for _, c := range parts {
+		show("decomposition "+tag, c)
+	}
+	ucd.Each(func(e ucd.Entry) {
+		switch r {
+		case e.Upper:
+			show("uppercase of", e.Code)
+		case e.Lower:
+			show("lowercase of", e.Code)
+		case e.Title:
+			show("titlecase of", e.Code)
+		}
+	})
+	ucd.Each(func(e ucd.Entry) {
+		for _, c := range e.Decomposition {
+			if c == r {
+				tag := e.DecompTag
+				if tag == "" {
+					tag = "canonical"
+				}
+				show("in decomposition "+tag, e.Code)
+				return
+			}
+		}
+	})
+	for _, a := range annotations(r) {
+		ref := strings.TrimPrefix(a, "→ ")
+		switch {
+		case ref == a:
+		case strings.HasPrefix(ref, "U+"):
+			show("see also", parseRune(strings.Fields(ref)[0][2:]))
+		default:
+			fmt.Printf("\tsee also: %s\n", ref)
+		}
+	}
+	for _, c := range confusablesOf(r) {
+		show("confusable", c)
+	}
+	for _, v := range variations(r) {
+		fmt.Printf("\tvariation sequence: %04X %s\n", v.selector, v.desc)
+	}
+}
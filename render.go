@@ -0,0 +1,352 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sheetColumns is the number of characters in a row of a contact sheet.
+const sheetColumns = 8
+
+// render draws the characters of the arguments with the font of -font into
+// the PNG or SVG file, chosen by its suffix: as a contact sheet with the
+// code point under each, or if the name holds a % verb, such as
+// U+%04X.png, as one image per character named by its code point.
+func render(file string, args []string) {
+	if *doFont == "" {
+		fatalf("-render needs a TrueType font, named by -font")
+	}
+	if *doFontSize <= 0 {
+		fatalf("-fontsize must be positive")
+	}
+	f, err := readFont(*doFont)
+	if err != nil {
+		fatalf("-font %s: %s", *doFont, err)
+	}
+	svg := false
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".png":
+	case ".svg":
+		svg = true
+	default:
+		fatalf("-render: %s: want a .png or .svg file", file)
+	}
+	var codes []rune
+	for _, a := range args {
+		for _, r := range a {
+			if f.glyphIndex(r) == 0 {
+				fmt.Fprintf(os.Stderr, "unicode: %s has no glyph for %U\n", *doFont, r)
+			}
+			codes = append(codes, r)
+		}
+	}
+	if len(codes) == 0 {
+		fatalf("-render: no characters")
+	}
+	if !strings.Contains(file, "%") {
+		writeImage(file, svg, f, codes, true)
+		return
+	}
+	for _, r := range codes {
+		writeImage(fmt.Sprintf(file, r), svg, f, []rune{r}, false)
+	}
+}
+
+// A canvas is the layout of an image of glyphs in pixels.
+type canvas struct {
+	f      *sfnt
+	scale  float64 // Pixels per font unit.
+	width  int
+	height int
+	cell   int  // The side of a cell of a contact sheet.
+	sheet  bool // Whether it is a contact sheet.
+}
+
+// newCanvas lays out an image of the codes: a contact sheet, or an image
+// of a single glyph with a margin around it.
+func newCanvas(f *sfnt, codes []rune, sheet bool) *canvas {
+	c := &canvas{f: f, scale: float64(*doFontSize) / float64(f.unitsPerEm), sheet: sheet}
+	if sheet {
+		c.cell = *doFontSize * 3 / 2
+		cols := sheetColumns
+		if len(codes) < cols {
+			cols = len(codes)
+		}
+		c.width = cols * c.cell
+		c.height = (len(codes) + sheetColumns - 1) / sheetColumns * c.cell
+		return c
+	}
+	margin := *doFontSize / 8
+	w := int(math.Ceil(float64(c.glyphWidth(codes[0])) * c.scale))
+	c.width = w + 2*margin
+	c.height = int(math.Ceil(float64(f.ascent-f.descent)*c.scale)) + 2*margin
+	return c
+}
+
+// glyphWidth returns the width of the glyph of r in font units: its
+// advance or, for a mark that does not advance, the width of its outline.
+func (c *canvas) glyphWidth(r rune) int {
+	g := c.f.glyphIndex(r)
+	if w := c.f.advance(g); w > 0 {
+		return w
+	}
+	lo, hi := bounds(path(c.f.outline(g)))
+	return int(hi.x - lo.x)
+}
+
+// origin returns the pixel position of the origin of the i'th glyph of
+// the image: centered in its cell of a contact sheet, or within the margin.
+func (c *canvas) origin(i int, r rune) vec {
+	g := c.f.glyphIndex(r)
+	x := 0.0
+	if c.f.advance(g) == 0 {
+		// Center the outline of a mark that does not advance.
+		lo, _ := bounds(path(c.f.outline(g)))
+		x = -lo.x * c.scale
+	}
+	if !c.sheet {
+		margin := float64(*doFontSize / 8)
+		return vec{margin + x, margin + float64(c.f.ascent)*c.scale}
+	}
+	col, row := i%sheetColumns, i/sheetColumns
+	x += float64(col*c.cell) + (float64(c.cell)-float64(c.glyphWidth(r))*c.scale)/2
+	y := float64(row*c.cell) + float64(*doFontSize)*0.15 + float64(c.f.ascent)*float64(*doFontSize)/float64(c.f.ascent-c.f.descent)
+	return vec{x, y}
+}
+
+// bounds returns the bounding box of the segments.
+func bounds(segs []segment) (lo, hi vec) {
+	lo = vec{math.Inf(1), math.Inf(1)}
+	hi = vec{math.Inf(-1), math.Inf(-1)}
+	for _, s := range segs {
+		pts := []vec{s.p}
+		if s.op == 'Q' {
+			pts = append(pts, s.c)
+		}
+		for _, p := range pts {
+			lo.x, lo.y = math.Min(lo.x, p.x), math.Min(lo.y, p.y)
+			hi.x, hi.y = math.Max(hi.x, p.x), math.Max(hi.y, p.y)
+		}
+	}
+	if lo.x > hi.x {
+		return vec{}, vec{}
+	}
+	return lo, hi
+}
+
+// writeImage writes the image of the codes to the named file.
+func writeImage(file string, svg bool, f *sfnt, codes []rune, sheet bool) {
+	c := newCanvas(f, codes, sheet)
+	var data []byte
+	if svg {
+		data = []byte(c.svg(codes))
+	} else {
+		var b bytes.Buffer
+		if err := png.Encode(&b, c.png(codes)); err != nil {
+			fatalf("-render: %s", err)
+		}
+		data = b.Bytes()
+	}
+	if err := os.WriteFile(file, data, 0o666); err != nil {
+		fatalf("-render: %s", err)
+	}
+}
+
+// label returns the text under a character of a contact sheet.
+func label(r rune) string {
+	return fmt.Sprintf("%04X", r)
+}
+
+// svg returns the image as SVG, with the outlines of the glyphs as paths
+// in font units, flipped and scaled into place.
+func (c *canvas) svg(codes []rune) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", c.width, c.height, c.width, c.height)
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+	for i, r := range codes {
+		o := c.origin(i, r)
+		fmt.Fprintf(&b, "<path transform=\"translate(%.2f %.2f) scale(%g %g)\" d=\"%s\"/>\n", o.x, o.y, c.scale, -c.scale, svgPath(path(c.f.outline(c.f.glyphIndex(r)))))
+		if c.sheet {
+			x := (i%sheetColumns)*c.cell + c.cell/2
+			y := (i/sheetColumns+1)*c.cell - c.cell/12
+			fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" font-family=\"monospace\" font-size=\"%d\" text-anchor=\"middle\">%s</text>\n", x, y, *doFontSize/5, label(r))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// svgPath returns the segments as the data of an SVG path.
+func svgPath(segs []segment) string {
+	var b strings.Builder
+	for i, s := range segs {
+		if s.op == 'M' && i > 0 {
+			b.WriteString("Z ")
+		}
+		switch s.op {
+		case 'Q':
+			fmt.Fprintf(&b, "Q%g %g %g %g ", s.c.x, s.c.y, s.p.x, s.p.y)
+		default:
+			fmt.Fprintf(&b, "%c%g %g ", s.op, s.p.x, s.p.y)
+		}
+	}
+	if len(segs) > 0 {
+		b.WriteString("Z")
+	}
+	return b.String()
+}
+
+// png returns the image as black glyphs on white, with the code points of
+// a contact sheet drawn in the same font.
+func (c *canvas) png(codes []rune) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, c.width, c.height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	for i, r := range codes {
+		c.draw(img, r, c.origin(i, r), c.scale)
+		if !c.sheet {
+			continue
+		}
+		// The label, a fifth of the size, centered at the foot of the cell,
+		// unless the font, perhaps one of symbols, has no digits.
+		scale := c.scale / 5
+		text := label(r)
+		w := 0
+		for _, l := range text {
+			g := c.f.glyphIndex(l)
+			if g == 0 {
+				w = -1
+				break
+			}
+			w += c.f.advance(g)
+		}
+		if w < 0 {
+			continue
+		}
+		x := float64((i%sheetColumns)*c.cell+c.cell/2) - float64(w)*scale/2
+		y := float64((i/sheetColumns+1)*c.cell - c.cell/12)
+		for _, l := range text {
+			c.draw(img, l, vec{x, y}, scale)
+			x += float64(c.f.advance(c.f.glyphIndex(l))) * scale
+		}
+	}
+	return img
+}
+
+// The rasterizer samples each pixel on a grid of subsamples×subsamples.
+const subsamples = 4
+
+// An edge is a line of an outline in pixels, directed down (dir 1) or up (-1).
+type edge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+// draw fills the outline of r, with its origin at o and scaled to pixels,
+// by the nonzero winding rule, darkening img by the area covered.
+func (c *canvas) draw(img *image.Gray, r rune, o vec, scale float64) {
+	toPixels := func(p vec) vec { return vec{o.x + p.x*scale, o.y - p.y*scale} }
+	var edges []edge
+	add := func(a, b vec) {
+		if a.y == b.y {
+			return
+		}
+		e := edge{a.x, a.y, b.x, b.y, 1}
+		if a.y > b.y {
+			e = edge{b.x, b.y, a.x, a.y, -1}
+		}
+		edges = append(edges, e)
+	}
+	var start, cur vec
+	for _, s := range path(c.f.outline(c.f.glyphIndex(r))) {
+		p := toPixels(s.p)
+		switch s.op {
+		case 'M':
+			add(cur, start)
+			start, cur = p, p
+			continue
+		case 'L':
+			add(cur, p)
+		case 'Q':
+			// Flatten the curve into lines.
+			ctrl := toPixels(s.c)
+			const n = 8
+			prev := cur
+			for k := 1; k <= n; k++ {
+				t := float64(k) / n
+				q := vec{
+					(1-t)*(1-t)*cur.x + 2*(1-t)*t*ctrl.x + t*t*p.x,
+					(1-t)*(1-t)*cur.y + 2*(1-t)*t*ctrl.y + t*t*p.y,
+				}
+				add(prev, q)
+				prev = q
+			}
+		}
+		cur = p
+	}
+	add(cur, start)
+	bounds := img.Bounds()
+	cover := make([]int, bounds.Dx())
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	var xs []crossing
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for i := range cover {
+			cover[i] = 0
+		}
+		touched := false
+		for sy := 0; sy < subsamples; sy++ {
+			y := float64(py) + (float64(sy)+0.5)/subsamples
+			xs = xs[:0]
+			for _, e := range edges {
+				if e.y0 <= y && y < e.y1 {
+					xs = append(xs, crossing{e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0), e.dir})
+				}
+			}
+			if len(xs) == 0 {
+				continue
+			}
+			touched = true
+			sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+			winding := 0
+			for i, x := range xs {
+				winding += x.dir
+				if winding == 0 || i+1 == len(xs) {
+					continue
+				}
+				// Fill the subsamples whose centers lie in [x.x, next x).
+				lo := int(math.Ceil(x.x*subsamples - 0.5))
+				hi := int(math.Ceil(xs[i+1].x*subsamples - 0.5))
+				for sx := lo; sx < hi; sx++ {
+					if px := sx / subsamples; sx >= 0 && px < len(cover) {
+						cover[px]++
+					}
+				}
+			}
+		}
+		if !touched {
+			continue
+		}
+		for px, n := range cover {
+			if n > 0 {
+				old := img.GrayAt(px, py).Y
+				img.SetGray(px, py, color.Gray{uint8(int(old) * (subsamples*subsamples - n) / (subsamples * subsamples))})
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// report prints a summary of the text of each named file, or of standard
+// input if there are none: its size in bytes, code points, grapheme
+// clusters and lines, the counts of its characters by script, category
+// and block, and the controls and bidi controls it holds.
+func report(files []string) {
+	eachInput(files, func(i int, name string, data []byte) {
+		if i > 0 {
+			fmt.Println()
+		}
+		reportText(name, data)
+	})
+}
+
+// eachInput calls fn with the contents of each named file, or of standard
+// input if there are none.
+func eachInput(files []string, fn func(i int, name string, data []byte)) {
+	if len(files) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		fn(0, "standard input", data)
+		return
+	}
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		fn(i, file, data)
+	}
+}
+
+// inputText returns data as text. Text with a byte order mark or in UTF-16
+// or UTF-32 is converted; other text is taken to be UTF-8, and its invalid
+// bytes are left for the caller to find rather than read as Latin-1.
+func inputText(data []byte) string {
+	if hasBOM(data) || guessEncoding(data) != "" {
+		return decodeInput(data)
+	}
+	checkUTF8("input", string(data))
+	return string(data)
+}
+
+// reportText prints the report of data.
+func reportText(name string, data []byte) {
+	text := inputText(data)
+	counts := make(map[rune]int)
+	runes, invalid := 0, 0
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+		i += n
+		if r == utf8.RuneError && n == 1 {
+			invalid++
+			continue
+		}
+		counts[r]++
+		runes++
+	}
+	lines := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		lines++
+	}
+	fmt.Printf("%s: %d bytes, %d code points, %d grapheme clusters, %d lines\n", name, len(data), runes, uniseg.GraphemeClusterCount(text), lines)
+	if invalid > 0 {
+		fmt.Printf("\tinvalid UTF-8 bytes: %d\n", invalid)
+	}
+	scripts := make(map[string]int)
+	cats := make(map[string]int)
+	blocks := make(map[string]int)
+	var controls, bidis []rune
+	for r, n := range counts {
+		scripts[script(r)] += n
+		cat := category(r)
+		if cat == "" {
+			cat = "Cn"
+		}
+		cats[cat] += n
+		blocks[block(r)] += n
+		switch {
+		case unicode.Is(unicode.Bidi_Control, r):
+			bidis = append(bidis, r)
+		case unicode.IsControl(r) && r != '\n':
+			controls = append(controls, r)
+		}
+	}
+	printTally("scripts", scripts, runes)
+	printTally("categories", cats, runes)
+	printTally("blocks", blocks, runes)
+	printOccurrences("controls", controls, counts)
+	printOccurrences("bidi controls", bidis, counts)
+}
+
+// hasBOM reports whether data begins with a byte order mark.
+func hasBOM(data []byte) bool {
+	for _, e := range inputEncodings {
+		if bytes.HasPrefix(data, e.bom) {
+			return true
+		}
+	}
+	return false
+}
+
+// printTally prints the counts of the tally, most frequent first, with
+// their percentages of total, under the heading.
+func printTally(heading string, tally map[string]int, total int) {
+	if len(tally) == 0 {
+		return
+	}
+	var keys []string
+	width := 0
+	for k := range tally {
+		keys = append(keys, k)
+		if len(k) > width {
+			width = len(k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if tally[keys[i]] != tally[keys[j]] {
+			return tally[keys[i]] > tally[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	fmt.Printf("%s:\n", heading)
+	for _, k := range keys {
+		fmt.Printf("\t%-*s %8d %5.1f%%\n", width, k, tally[k], 100*float64(tally[k])/float64(total))
+	}
+}
+
+// printOccurrences prints each of the runes, in order, with its count
+// and name, under the heading.
+func printOccurrences(heading string, runes []rune, counts map[rune]int) {
+	if len(runes) == 0 {
+		return
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	fmt.Printf("%s:\n", heading)
+	for _, r := range runes {
+		fmt.Printf("\t%U %8d %s\n", r, counts[r], strings.ToLower(ucd.Name(r)))
+	}
+}
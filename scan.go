@@ -0,0 +1,213 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// scan checks the source files named, and the files in the directories
+// named, or in the current directory if none are, for the Unicode that can
+// make code read differently from how it compiles, as in the Trojan Source
+// attacks: bidi controls anywhere, invisible characters, and identifiers
+// that mix scripts, look like other identifiers in ASCII, or are not in
+// NFC. Hidden directories such as .git and binary files are skipped. Each
+// problem is printed as file:line:column, counting characters, and if any
+// are found scan exits with status 1.
+func scan(paths []string) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	problems := 0
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case info.IsDir():
+				if path != root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			case !info.Mode().IsRegular():
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if !isBinary(data) {
+				problems += scanSource(path, string(data))
+			}
+			return nil
+		})
+		if err != nil {
+			fatalf("%s", err)
+		}
+	}
+	if problems > 0 {
+		fmt.Fprintf(os.Stderr, "unicode: %d problems found\n", problems)
+		exit(1)
+	}
+}
+
+// isBinary reports whether data, the contents of a file, holds a zero
+// byte near its start, as text does not.
+func isBinary(data []byte) bool {
+	if len(data) > 8192 {
+		data = data[:8192]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// A sourceScanner finds the problems of the text of a source file. It
+// knows no language, only that quotes delimit string literals, which end
+// at the end of the line unless the quote is a backquote.
+type sourceScanner struct {
+	file      string
+	line, col int
+	problems  int
+}
+
+func (s *sourceScanner) report(line, col int, format string, args ...interface{}) {
+	fmt.Printf("%s:%d:%d: %s\n", s.file, line, col, fmt.Sprintf(format, args...))
+	s.problems++
+}
+
+// scanSource prints the problems of the source text of the file and
+// returns how many there are.
+func scanSource(file, text string) int {
+	s := &sourceScanner{file: file, line: 1, col: 1}
+	if !utf8.ValidString(text) {
+		s.report(1, 1, "not valid UTF-8")
+	}
+	text = strings.TrimPrefix(text, "\uFEFF")
+	quote, escaped := rune(0), false
+	var ident []rune
+	identLine, identCol := 0, 0
+	for _, r := range text {
+		inIdent := len(ident) > 0 && (isSourceIdentContinue(r) || isDefaultIgnorable(r) && !unicode.Is(unicode.Bidi_Control, r))
+		if len(ident) > 0 && !inIdent {
+			s.checkIdent(identLine, identCol, string(ident))
+			ident = ident[:0]
+		}
+		switch {
+		case unicode.Is(unicode.Bidi_Control, r):
+			s.report(s.line, s.col, "%U %s: bidi control%s", r, strings.ToLower(ucd.Name(r)), where(quote, inIdent))
+		case isDefaultIgnorable(r):
+			s.report(s.line, s.col, "%U %s: invisible%s", r, strings.ToLower(ucd.Name(r)), where(quote, inIdent))
+		}
+		switch {
+		case inIdent:
+			ident = append(ident, r)
+		case quote != 0:
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\' && quote != '`':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case isSourceIdentStart(r):
+			ident = append(ident, r)
+			identLine, identCol = s.line, s.col
+		}
+		if r == '\n' {
+			s.line, s.col = s.line+1, 1
+			if quote != '`' {
+				quote, escaped = 0, false
+			}
+			continue
+		}
+		s.col++
+	}
+	if len(ident) > 0 {
+		s.checkIdent(identLine, identCol, string(ident))
+	}
+	return s.problems
+}
+
+// where returns where a character is, to follow the report of a problem:
+// in a string literal delimited by quote, if not 0, or in an identifier.
+func where(quote rune, inIdent bool) string {
+	switch {
+	case quote != 0:
+		return " in a string literal"
+	case inIdent:
+		return " in an identifier"
+	}
+	return ""
+}
+
+// isSourceIdentStart and isSourceIdentContinue are XID_Start and
+// XID_Continue with the underscore of most languages, quick for ASCII.
+func isSourceIdentStart(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+	}
+	return isXIDStart(r)
+}
+
+func isSourceIdentContinue(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isSourceIdentStart(r) || '0' <= r && r <= '9'
+	}
+	return isXIDContinue(r)
+}
+
+// checkIdent reports the problems of a non-ASCII identifier: it is not in
+// NFC, so may differ from an identifier that looks the same, or it mixes
+// scripts beyond the highly restrictive level of UTS #39, or its skeleton
+// is ASCII, so that it looks like an ASCII identifier (if confusables.txt
+// is installed).
+func (s *sourceScanner) checkIdent(line, col int, id string) {
+	if isASCII(id) {
+		return
+	}
+	if !norm.NFC.IsNormalString(id) {
+		s.report(line, col, "identifier %+q is not in NFC, which is %+q", id, norm.NFC.String(id))
+	}
+	var scripts []string
+	for _, r := range id {
+		if sc := script(r); sc != "Common" && sc != "Inherited" && !subset([]string{sc}, scripts) {
+			scripts = append(scripts, sc)
+		}
+	}
+	if len(scripts) > 1 {
+		switch restrictionLevel(id, scripts) {
+		case "moderately restrictive", "minimally restrictive":
+			s.report(line, col, "identifier %q mixes scripts %s", id, strings.Join(scripts, ", "))
+		}
+	}
+	if confusables == nil && readUCD("confusables.txt") == "" {
+		return
+	}
+	loadConfusables()
+	if sk := skeleton(id); sk != id && isASCII(sk) {
+		s.report(line, col, "identifier %q looks like %q", id, sk)
+	}
+}
+
+// isASCII reports whether s is all ASCII.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+var (
+	//go:embed ScriptExtensions.txt.gz
+	scriptExtensionsGz  []byte
+	scriptExtensionList []rangeValue
+
+	//go:embed PropertyValueAliases.txt.gz
+	propertyValueAliasesGz []byte
+	scriptCodes            map[string]string // Script name to four-letter code, such as Devanagari to Deva.
+	scriptsByLooseName     map[string]string // Loose name or code to script name.
+)
+
+func loadScriptAliases() {
+	if scriptCodes != nil {
+		return
+	}
+	scriptCodes = make(map[string]string)
+	scriptsByLooseName = make(map[string]string)
+	for i, line := range splitLines(ucdText("PropertyValueAliases.txt", propertyValueAliasesGz)) {
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+		fields := strings.Split(line, ";")
+		for j := range fields {
+			fields[j] = strings.TrimSpace(fields[j])
+		}
+		if fields[0] != "sc" {
+			continue
+		}
+		if len(fields) < 3 {
+			fatalf("malformed PropertyValueAliases.txt: line %d", i+1)
+		}
+		code, name := fields[1], fields[2]
+		scriptCodes[name] = code
+		for _, alias := range fields[1:] {
+			scriptsByLooseName[looseName(alias)] = name
+		}
+	}
+	// Scripts newer than the aliases are known only by name.
+	for name := range unicode.Scripts {
+		if _, ok := scriptCodes[name]; !ok {
+			scriptsByLooseName[looseName(name)] = name
+		}
+	}
+}
+
+// scriptName returns the name of the script given by name or by code,
+// such as Deva, in the form used by package unicode.
+func scriptName(s string) string {
+	loadScriptAliases()
+	name, ok := scriptsByLooseName[looseName(s)]
+	if !ok {
+		fatalf("unknown script %q", s)
+	}
+	return name
+}
+
+// scriptExtensions returns the names of the scripts with which r is used.
+// For most characters that is just its script.
+func scriptExtensions(r rune) []string {
+	if scriptExtensionList == nil {
+		scriptExtensionList = parseRanges(ucdText("ScriptExtensions.txt", scriptExtensionsGz))
+	}
+	f := lookupRange(scriptExtensionList, r)
+	if f == nil {
+		return []string{script(r)}
+	}
+	var names []string
+	for _, code := range strings.Fields(f[0]) {
+		names = append(names, scriptName(code))
+	}
+	return names
+}
+
+// describeScript returns the script of r, followed by its script
+// extensions if they say more.
+func describeScript(r rune) string {
+	sc := script(r)
+	scx := scriptExtensions(r)
+	if len(scx) == 1 && scx[0] == sc {
+		return sc
+	}
+	return sc + " (extensions: " + strings.Join(scx, ", ") + ")"
+}
+
+// inScripts returns the runes of codes that are used with any of the
+// scripts in the comma-separated list scripts, according to their
+// script extensions.
+func inScripts(codes []rune, scripts string) []rune {
+	want := make(map[string]bool)
+	for _, s := range strings.Split(scripts, ",") {
+		want[scriptName(s)] = true
+	}
+	var out []rune
+	for _, r := range codes {
+		for _, name := range scriptExtensions(r) {
+			if want[name] {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out
+}
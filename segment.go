@@ -0,0 +1,90 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// The text segmentation of UAX #29 is done by github.com/rivo/uniseg.
+// The break properties it uses are embedded to show how it was done.
+
+// graphemes prints each argument, or each line of standard input if there
+// are none, split into extended grapheme clusters, each with its code points.
+func graphemes(args []string) {
+	for _, a := range argsOrStdin(args) {
+		n := uniseg.GraphemeClusterCount(a)
+		fmt.Printf("'%s': %d grapheme clusters, %d code points, %d bytes\n", a, n, utf8.RuneCountInString(a), len(a))
+		g := uniseg.NewGraphemes(a)
+		for i := 1; g.Next(); i++ {
+			fmt.Printf("%d\t'%s'\t%s\n", i, g.Str(), codePoints(g.Str()))
+		}
+	}
+}
+
+var (
+	//go:embed WordBreakProperty.txt.gz
+	wordBreakGz []byte
+	//go:embed SentenceBreakProperty.txt.gz
+	sentenceBreakGz []byte
+	wordBreaks      []rangeValue
+	sentenceBreaks  []rangeValue
+)
+
+// wordBreak returns the Word_Break property of r.
+func wordBreak(r rune) string {
+	if wordBreaks == nil {
+		wordBreaks = parseRanges(ucdText("WordBreakProperty.txt", wordBreakGz))
+	}
+	if f := lookupRange(wordBreaks, r); f != nil {
+		return f[0]
+	}
+	return "Other"
+}
+
+// sentenceBreak returns the Sentence_Break property of r.
+func sentenceBreak(r rune) string {
+	if sentenceBreaks == nil {
+		sentenceBreaks = parseRanges(ucdText("SentenceBreakProperty.txt", sentenceBreakGz))
+	}
+	if f := lookupRange(sentenceBreaks, r); f != nil {
+		return f[0]
+	}
+	return "Other"
+}
+
+// segments prints each argument, or each line of standard input if there
+// are none, split into words or sentences by the function first, which
+// is one of uniseg.FirstWordInString and uniseg.FirstSentenceInString.
+// Each segment is preceded by the byte offset of its boundary and followed
+// by its runes with their break property, as given by prop.
+func segments(args []string, first func(string, int) (string, string, int), prop func(rune) string) {
+	for _, a := range argsOrStdin(args) {
+		fmt.Printf("'%s'\n", a)
+		state := -1
+		for pos, rest := 0, a; rest != ""; {
+			var seg string
+			seg, rest, state = first(rest, state)
+			fmt.Printf("%d\t'%s'\n", pos, seg)
+			for _, r := range seg {
+				fmt.Printf("\t%U '%c' %s\n", r, r, prop(r))
+			}
+			pos += len(seg)
+		}
+		fmt.Printf("%d\n", len(a))
+	}
+}
+
+func words(args []string) {
+	segments(args, uniseg.FirstWordInString, wordBreak)
+}
+
+func sentences(args []string) {
+	segments(args, uniseg.FirstSentenceInString, sentenceBreak)
+}
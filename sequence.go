@@ -0,0 +1,109 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	//go:embed NamedSequences.txt.gz
+	namedSequencesGz []byte
+	namedSeqs        []namedSeq
+)
+
+// A namedSeq is a named character sequence.
+type namedSeq struct {
+	name string
+	text string
+}
+
+func loadNamedSequences() {
+	if namedSeqs != nil {
+		return
+	}
+	for i, line := range splitLines(ucdText("NamedSequences.txt", namedSequencesGz)) {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		semi := strings.IndexByte(line, ';')
+		if semi < 0 {
+			fatalf("malformed NamedSequences.txt: line %d", i+1)
+		}
+		var codes []rune
+		for _, f := range strings.Fields(line[semi+1:]) {
+			codes = append(codes, parseRune(f))
+		}
+		namedSeqs = append(namedSeqs, namedSeq{strings.TrimSpace(line[:semi]), string(codes)})
+	}
+}
+
+// grepSequences returns the named sequences whose names match the argument regexps.
+func grepSequences() []namedSeq {
+	loadNamedSequences()
+	var seqs []namedSeq
+	for _, a := range flag.Args() {
+		re, err := regexp.Compile(a)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		for _, s := range namedSeqs {
+			name := s.name
+			if !*doCase {
+				name = strings.ToLower(name)
+			}
+			if re.MatchString(name) {
+				seqs = append(seqs, s)
+			}
+		}
+	}
+	return seqs
+}
+
+// argsAreSequences returns the named sequences that are spelled by an argument.
+func argsAreSequences() []namedSeq {
+	loadNamedSequences()
+	var seqs []namedSeq
+	for _, a := range flag.Args() {
+		for _, s := range namedSeqs {
+			if a == s.text {
+				seqs = append(seqs, s)
+			}
+		}
+	}
+	return seqs
+}
+
+// printSequences prints seqs in the format selected by the flags.
+func printSequences(seqs []namedSeq) {
+	for _, s := range seqs {
+		switch {
+		case *doUnic || *doUNIC:
+			fmt.Printf("%s '%s' %s%s\n", codePoints(s.text), s.text, s.name, localSuffix(s.text))
+		case *doDesc:
+			fmt.Printf("%s '%s' %s%s\n", codePoints(s.text), s.text, strings.ToLower(s.name), localSuffix(s.text))
+		case *doChar || *doText:
+			fmt.Println(s.text)
+		default:
+			fmt.Println(strings.ToLower(strings.ReplaceAll(codePoints(s.text), "U+", "")))
+		}
+	}
+}
+
+// codePoints returns the code points of s in U+ notation, separated by spaces.
+func codePoints(s string) string {
+	var b strings.Builder
+	for i, r := range []rune(s) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "U+%04X", r)
+	}
+	return b.String()
+}
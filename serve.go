@@ -0,0 +1,157 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// A charInfo is the description of a character served by -serve.
+type charInfo struct {
+	Code           string   `json:"code"`
+	Char           string   `json:"char"`
+	Name           string   `json:"name"`
+	Category       string   `json:"category"`
+	CombiningClass uint8    `json:"combiningClass"`
+	BidiClass      string   `json:"bidiClass"`
+	Decomposition  string   `json:"decomposition,omitempty"`
+	Numeric        string   `json:"numeric,omitempty"`
+	Mirrored       bool     `json:"mirrored"`
+	OldName        string   `json:"oldName,omitempty"`
+	Upper          string   `json:"upper,omitempty"`
+	Lower          string   `json:"lower,omitempty"`
+	Title          string   `json:"title,omitempty"`
+	Aliases        []string `json:"aliases,omitempty"`
+	Script         string   `json:"script"`
+	Age            string   `json:"age,omitempty"`
+	Properties     []string `json:"properties,omitempty"`
+}
+
+// The property tables of this package are loaded on first use and are not
+// safe for concurrent use, so the server answers one query at a time.
+var serveMu sync.Mutex
+
+func newCharInfo(e ucd.Entry) charInfo {
+	r := e.Code
+	c := charInfo{
+		Code:           fmt.Sprintf("U+%04X", r),
+		Char:           string(r),
+		Name:           ucd.Name(r),
+		Category:       e.Category,
+		CombiningClass: e.CombiningClass,
+		BidiClass:      e.BidiClass,
+		Numeric:        e.Numeric,
+		Mirrored:       e.BidiMirrored,
+		OldName:        e.OldName,
+		Script:         describeScript(r),
+		Age:            age(r),
+		Properties:     properties(r),
+	}
+	if len(e.Decomposition) > 0 {
+		c.Decomposition = strings.TrimSpace(e.DecompTag + " " + codePoints(string(e.Decomposition)))
+	}
+	for _, m := range []struct {
+		to  rune
+		out *string
+	}{{e.Upper, &c.Upper}, {e.Lower, &c.Lower}, {e.Title, &c.Title}} {
+		if m.to != 0 {
+			*m.out = fmt.Sprintf("U+%04X", m.to)
+		}
+	}
+	for _, a := range ucd.Aliases(r) {
+		c.Aliases = append(c.Aliases, a.String())
+	}
+	return c
+}
+
+// serve answers queries over HTTP on addr:
+//
+//	/char/1F600          the character U+1F600
+//	/search?q=greek.*pi  the characters whose names match the regexp
+//
+// The answers are JSON, or HTML for clients, such as browsers, that accept it.
+func serve(addr string) {
+	http.HandleFunc("/char/", serveChar)
+	http.HandleFunc("/search", serveSearch)
+	http.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(w, req)
+			return
+		}
+		reply(w, req, "", nil)
+	})
+	fmt.Printf("serving on %s\n", addr)
+	fatalf("%s", http.ListenAndServe(addr, nil))
+}
+
+func serveChar(w http.ResponseWriter, req *http.Request) {
+	arg := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, "/char/"), "U+")
+	r, err := strconv.ParseInt(arg, 16, 32)
+	if err != nil || r > 0x10FFFF {
+		http.Error(w, fmt.Sprintf("bad code point %q", arg), http.StatusBadRequest)
+		return
+	}
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	e := ucd.Lookup(rune(r))
+	if !e.Assigned() {
+		http.Error(w, fmt.Sprintf("%U is unassigned", r), http.StatusNotFound)
+		return
+	}
+	reply(w, req, "", []charInfo{newCharInfo(e)})
+}
+
+func serveSearch(w http.ResponseWriter, req *http.Request) {
+	q := req.FormValue("q")
+	re, err := regexp.Compile(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	chars := []charInfo{}
+	for _, e := range ucd.Search(re) {
+		chars = append(chars, newCharInfo(e))
+	}
+	reply(w, req, q, chars)
+}
+
+// reply writes chars as JSON, or as an HTML page if the client accepts HTML.
+func reply(w http.ResponseWriter, req *http.Request, query string, chars []charInfo) {
+	if !strings.Contains(req.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(chars)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(w, struct {
+		Query string
+		Chars []charInfo
+	}{query, chars})
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>unicode</title></head>
+<body>
+<form action="/search"><input name="q" value="{{.Query}}" autofocus> <input type="submit" value="Search"></form>
+{{if .Chars}}<table>
+{{range .Chars}}<tr><td><a href="/char/{{slice .Code 2}}">{{.Code}}</a></td><td>{{.Char}}</td><td>{{.Name}}</td><td>{{.Category}}</td><td>{{.Script}}</td><td>{{.Age}}</td></tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,382 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// This file reads the glyph outlines of TrueType fonts, enough for
+// -render: the character map, the metrics, and the quadratic outlines
+// of the glyf table, including composite glyphs. Fonts with PostScript
+// (CFF) outlines are not supported.
+
+// An sfnt is a TrueType font.
+type sfnt struct {
+	tables     map[string][]byte
+	unitsPerEm int
+	ascent     int // Above the baseline, in font units.
+	descent    int // Below the baseline, negative, in font units.
+	numGlyphs  int
+	longLoca   bool
+	nHMetrics  int
+	cmap       []byte // The chosen subtable of the cmap table.
+}
+
+// A vec is a point in font units or pixels.
+type vec struct{ x, y float64 }
+
+// A segment is one step of an outline: a move to p, a line to p, or a
+// quadratic curve through the control point c to p.
+type segment struct {
+	op   byte // 'M', 'L' or 'Q'.
+	c, p vec
+}
+
+func u16(b []byte, i int) int {
+	if i < 0 || i+2 > len(b) {
+		return 0
+	}
+	return int(b[i])<<8 | int(b[i+1])
+}
+
+func i16(b []byte, i int) int {
+	return int(int16(u16(b, i)))
+}
+
+func u32(b []byte, i int) int {
+	return u16(b, i)<<16 | u16(b, i+2)
+}
+
+// readFont reads the TrueType font, or the first font of a collection,
+// in the named file.
+func readFont(name string) (*sfnt, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 {
+		return nil, errors.New("not a font")
+	}
+	base := 0
+	switch string(data[:4]) {
+	case "ttcf":
+		base = u32(data, 12)
+	case "OTTO":
+		return nil, errors.New("fonts with PostScript outlines are not supported; use a TrueType font")
+	case "\x00\x01\x00\x00", "true":
+	default:
+		return nil, errors.New("not a TrueType font")
+	}
+	tables, err := tableDirectory(data, base)
+	if err != nil {
+		return nil, err
+	}
+	f := &sfnt{tables: tables}
+	for _, t := range []string{"head", "hhea", "hmtx", "maxp", "cmap", "loca", "glyf"} {
+		if f.tables[t] == nil {
+			return nil, fmt.Errorf("no %s table; not a TrueType font", t)
+		}
+	}
+	head, hhea := f.tables["head"], f.tables["hhea"]
+	f.unitsPerEm = u16(head, 18)
+	f.longLoca = i16(head, 50) != 0
+	f.ascent, f.descent = i16(hhea, 4), i16(hhea, 6)
+	f.nHMetrics = u16(hhea, 34)
+	f.numGlyphs = u16(f.tables["maxp"], 4)
+	if f.unitsPerEm == 0 || f.nHMetrics == 0 {
+		return nil, errors.New("bad head or hhea table")
+	}
+	f.cmap = chooseCmap(f.tables["cmap"])
+	if f.cmap == nil {
+		return nil, errors.New("no Unicode character map")
+	}
+	return f, nil
+}
+
+// tableDirectory returns the tables of the font whose table directory is
+// at offset base of data, by tag.
+func tableDirectory(data []byte, base int) (map[string][]byte, error) {
+	tables := make(map[string][]byte)
+	n := u16(data, base+4)
+	for i := 0; i < n; i++ {
+		rec := base + 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, errors.New("truncated table directory")
+		}
+		off, length := u32(data, rec+8), u32(data, rec+12)
+		if off+length > len(data) {
+			return nil, fmt.Errorf("table %q out of range", data[rec:rec+4])
+		}
+		tables[string(data[rec:rec+4])] = data[off : off+length]
+	}
+	return tables, nil
+}
+
+// chooseCmap returns the Unicode subtable of the cmap, preferring format
+// 12, which covers the supplementary planes, to format 4.
+func chooseCmap(cmap []byte) []byte {
+	var best []byte
+	for i := 0; i < u16(cmap, 2); i++ {
+		rec := 4 + 8*i
+		platform, encoding, off := u16(cmap, rec), u16(cmap, rec+2), u32(cmap, rec+4)
+		if off >= len(cmap) {
+			continue
+		}
+		unicode := platform == 0 || platform == 3 && (encoding == 0 || encoding == 1 || encoding == 10)
+		switch sub := cmap[off:]; {
+		case !unicode:
+		case u16(sub, 0) == 12:
+			return sub
+		case u16(sub, 0) == 4 && best == nil:
+			best = sub
+		}
+	}
+	return best
+}
+
+// glyphIndex returns the glyph for r, or 0, the missing glyph.
+func (f *sfnt) glyphIndex(r rune) int {
+	c := int(r)
+	t := f.cmap
+	if u16(t, 0) == 12 {
+		for i := 0; i < u32(t, 12); i++ {
+			g := 16 + 12*i
+			if g+12 > len(t) {
+				break
+			}
+			if lo, hi := u32(t, g), u32(t, g+4); lo <= c && c <= hi {
+				return u32(t, g+8) + c - lo
+			}
+		}
+		return 0
+	}
+	if c > 0xFFFF {
+		return 0
+	}
+	segX2 := u16(t, 6)
+	for i := 0; i < segX2; i += 2 {
+		end, start := u16(t, 14+i), u16(t, 16+segX2+i)
+		if c < start || end < c {
+			continue
+		}
+		delta := u16(t, 16+2*segX2+i)
+		roPos := 16 + 3*segX2 + i
+		ro := u16(t, roPos)
+		if ro == 0 {
+			return (c + delta) & 0xFFFF
+		}
+		g := u16(t, roPos+ro+2*(c-start))
+		if g != 0 {
+			g = (g + delta) & 0xFFFF
+		}
+		return g
+	}
+	return 0
+}
+
+// advance returns the advance width of glyph g in font units.
+func (f *sfnt) advance(g int) int {
+	if g >= f.nHMetrics {
+		g = f.nHMetrics - 1
+	}
+	return u16(f.tables["hmtx"], 4*g)
+}
+
+// glyphData returns the glyf table entry of glyph g.
+func (f *sfnt) glyphData(g int) []byte {
+	loca, glyf := f.tables["loca"], f.tables["glyf"]
+	var lo, hi int
+	if f.longLoca {
+		lo, hi = u32(loca, 4*g), u32(loca, 4*g+4)
+	} else {
+		lo, hi = 2*u16(loca, 2*g), 2*u16(loca, 2*g+2)
+	}
+	if g >= f.numGlyphs || lo >= hi || hi > len(glyf) {
+		return nil
+	}
+	return glyf[lo:hi]
+}
+
+// A glyphPoint is a point of a TrueType contour, on or off the curve.
+type glyphPoint struct {
+	vec
+	on bool
+}
+
+// outline returns the contours of glyph g in font units.
+func (f *sfnt) outline(g int) [][]glyphPoint {
+	return f.contours(g, 0)
+}
+
+func (f *sfnt) contours(g, depth int) [][]glyphPoint {
+	b := f.glyphData(g)
+	if len(b) < 10 || depth > 8 {
+		return nil
+	}
+	n := i16(b, 0)
+	if n < 0 {
+		return f.composite(b, depth)
+	}
+	// A simple glyph: contour ends, instructions, flags, then coordinates.
+	ends := make([]int, n)
+	for i := range ends {
+		ends[i] = u16(b, 10+2*i)
+	}
+	if n == 0 {
+		return nil
+	}
+	np := ends[n-1] + 1
+	p := 10 + 2*n
+	p += 2 + u16(b, p)
+	flags := make([]byte, 0, np)
+	for len(flags) < np && p < len(b) {
+		fl := b[p]
+		p++
+		flags = append(flags, fl)
+		if fl&8 != 0 && p < len(b) {
+			for k := int(b[p]); k > 0 && len(flags) < np; k-- {
+				flags = append(flags, fl)
+			}
+			p++
+		}
+	}
+	if len(flags) < np {
+		return nil
+	}
+	pts := make([]glyphPoint, np)
+	coords := func(short, same byte, set func(i, v int)) {
+		v := 0
+		for i, fl := range flags {
+			switch {
+			case fl&short != 0:
+				d := 0
+				if p < len(b) {
+					d = int(b[p])
+				}
+				p++
+				if fl&same == 0 {
+					d = -d
+				}
+				v += d
+			case fl&same == 0:
+				v += i16(b, p)
+				p += 2
+			}
+			set(i, v)
+		}
+	}
+	coords(2, 16, func(i, v int) { pts[i].x = float64(v) })
+	coords(4, 32, func(i, v int) { pts[i].y = float64(v) })
+	var out [][]glyphPoint
+	start := 0
+	for i, fl := range flags {
+		pts[i].on = fl&1 != 0
+	}
+	for _, end := range ends {
+		if end < start || end >= np {
+			break
+		}
+		out = append(out, pts[start:end+1])
+		start = end + 1
+	}
+	return out
+}
+
+// composite returns the contours of a composite glyph, whose components
+// are other glyphs, each moved and perhaps scaled.
+func (f *sfnt) composite(b []byte, depth int) [][]glyphPoint {
+	var out [][]glyphPoint
+	for p := 10; p+4 <= len(b); {
+		flags, g := u16(b, p), u16(b, p+2)
+		p += 4
+		var dx, dy int
+		if flags&1 != 0 { // ARG_1_AND_2_ARE_WORDS
+			dx, dy = i16(b, p), i16(b, p+2)
+			p += 4
+		} else if p+2 <= len(b) {
+			dx, dy = int(int8(b[p])), int(int8(b[p+1]))
+			p += 2
+		}
+		if flags&2 == 0 { // Matching points rather than offsets; not supported.
+			dx, dy = 0, 0
+		}
+		a, bb, c, d := 1.0, 0.0, 0.0, 1.0
+		f2dot14 := func(i int) float64 { return float64(i16(b, i)) / 16384 }
+		switch {
+		case flags&8 != 0: // WE_HAVE_A_SCALE
+			a = f2dot14(p)
+			d = a
+			p += 2
+		case flags&0x40 != 0: // WE_HAVE_AN_X_AND_Y_SCALE
+			a, d = f2dot14(p), f2dot14(p+2)
+			p += 4
+		case flags&0x80 != 0: // WE_HAVE_A_TWO_BY_TWO
+			a, bb, c, d = f2dot14(p), f2dot14(p+2), f2dot14(p+4), f2dot14(p+6)
+			p += 8
+		}
+		for _, contour := range f.contours(g, depth+1) {
+			moved := make([]glyphPoint, len(contour))
+			for i, pt := range contour {
+				moved[i] = glyphPoint{vec{a*pt.x + c*pt.y + float64(dx), bb*pt.x + d*pt.y + float64(dy)}, pt.on}
+			}
+			out = append(out, moved)
+		}
+		if flags&0x20 == 0 { // MORE_COMPONENTS
+			break
+		}
+	}
+	return out
+}
+
+// path returns the segments of the contours: for each, a move, then lines
+// and curves. Between two points off the curve lies an implied point on it.
+func path(contours [][]glyphPoint) []segment {
+	var segs []segment
+	mid := func(a, b vec) vec { return vec{(a.x + b.x) / 2, (a.y + b.y) / 2} }
+	for _, pts := range contours {
+		if len(pts) == 0 {
+			continue
+		}
+		// Start at a point on the curve.
+		var start vec
+		first := 0
+		switch {
+		case pts[0].on:
+			start, first = pts[0].vec, 1
+		case pts[len(pts)-1].on:
+			start = pts[len(pts)-1].vec
+		default:
+			start = mid(pts[len(pts)-1].vec, pts[0].vec)
+		}
+		segs = append(segs, segment{op: 'M', p: start})
+		var ctrl *vec
+		for i := first; i <= len(pts); i++ {
+			var pt glyphPoint
+			if i == len(pts) {
+				pt = glyphPoint{start, true}
+			} else {
+				pt = pts[i]
+			}
+			switch {
+			case pt.on && ctrl == nil:
+				segs = append(segs, segment{op: 'L', p: pt.vec})
+			case pt.on:
+				segs = append(segs, segment{op: 'Q', c: *ctrl, p: pt.vec})
+				ctrl = nil
+			case ctrl != nil:
+				segs = append(segs, segment{op: 'Q', c: *ctrl, p: mid(*ctrl, pt.vec)})
+				v := pt.vec
+				ctrl = &v
+			default:
+				v := pt.vec
+				ctrl = &v
+			}
+		}
+	}
+	return segs
+}
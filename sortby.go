@@ -0,0 +1,55 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// sortOrders are the orders of -sortby, each comparing two characters.
+// Characters that compare equal stay in code point order.
+var sortOrders = map[string]func(a, b rune) int{
+	"code": func(a, b rune) int {
+		return 0
+	},
+	"name": func(a, b rune) int {
+		return strings.Compare(ucd.Name(a), ucd.Name(b))
+	},
+	"category": func(a, b rune) int {
+		return strings.Compare(category(a), category(b))
+	},
+	"block": func(a, b rune) int {
+		return strings.Compare(block(a), block(b))
+	},
+	"age": func(a, b rune) int {
+		return compareInts(ageOrder(a), ageOrder(b))
+	},
+}
+
+// ageOrder returns the version of Unicode that assigned r as a number
+// that sorts correctly, with unassigned characters last.
+func ageOrder(r rune) int {
+	if a := age(r); a != "" {
+		return version(a)
+	}
+	return 1 << 30
+}
+
+// sortBy sorts codes by the order named by key.
+func sortBy(codes []rune, key string) {
+	cmp := sortOrders[key]
+	if cmp == nil {
+		fatalf("-sortby: unknown order %q; want code, name, category, block or age", key)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if c := cmp(codes[i], codes[j]); c != 0 {
+			return c < 0
+		}
+		return codes[i] < codes[j]
+	})
+}
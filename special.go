@@ -0,0 +1,82 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+)
+
+var (
+	//go:embed SpecialCasing.txt.gz
+	specialCasingGz []byte
+	specialCases    map[rune][]specialCase
+)
+
+// A specialCase is an entry of SpecialCasing.txt: a case mapping that
+// changes the length of the text or depends on its context or language.
+type specialCase struct {
+	lower, title, upper string
+	condition           string // Such as "Final_Sigma" or "tr After_I"; empty if unconditional.
+}
+
+func (c specialCase) String() string {
+	var list []string
+	for _, m := range []struct{ kind, text string }{
+		{"lower", c.lower},
+		{"title", c.title},
+		{"upper", c.upper},
+	} {
+		list = append(list, fmt.Sprintf("%s %s", m.kind, codePoints(m.text)))
+	}
+	s := strings.Join(list, ", ")
+	if c.condition != "" {
+		s += " (" + c.condition + ")"
+	}
+	return s
+}
+
+func loadSpecialCasing() {
+	if specialCases != nil {
+		return
+	}
+	specialCases = make(map[rune][]specialCase)
+	for i, line := range splitLines(ucdText("SpecialCasing.txt", specialCasingGz)) {
+		if j := strings.IndexByte(line, '#'); j >= 0 {
+			line = line[:j]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 5 {
+			fatalf("malformed SpecialCasing.txt: line %d", i+1)
+		}
+		mapping := func(s string) string {
+			var codes []rune
+			for _, f := range strings.Fields(s) {
+				codes = append(codes, parseRune(f))
+			}
+			return string(codes)
+		}
+		r := parseRune(fields[0])
+		c := specialCase{
+			lower: mapping(fields[1]),
+			title: mapping(fields[2]),
+			upper: mapping(fields[3]),
+		}
+		if len(fields) > 5 {
+			c.condition = strings.TrimSpace(fields[4])
+		}
+		specialCases[r] = append(specialCases[r], c)
+	}
+}
+
+// specialCasing returns the special case mappings of r.
+func specialCasing(r rune) []specialCase {
+	loadSpecialCasing()
+	return specialCases[r]
+}
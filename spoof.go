@@ -0,0 +1,145 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// scriptNames holds the names of the scripts of package unicode, sorted.
+var scriptNames []string
+
+// script returns the name of the script of r, or "Unknown".
+func script(r rune) string {
+	if scriptNames == nil {
+		for name := range unicode.Scripts {
+			scriptNames = append(scriptNames, name)
+		}
+		sort.Strings(scriptNames)
+	}
+	for _, name := range scriptNames {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+// highlyRestrictive lists the combinations of scripts that UTS #39
+// allows in a highly restrictive string.
+var highlyRestrictive = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// restrictionLevel returns the restriction level of UTS #39 of a string
+// that uses the given scripts, not counting Common and Inherited.
+// The profile for identifiers is approximated by XID_Continue, so
+// a string with other characters is unrestricted.
+func restrictionLevel(s string, scripts []string) string {
+	ascii := true
+	for _, r := range s {
+		if !isXIDContinue(r) {
+			return "unrestricted"
+		}
+		if r >= 0x80 {
+			ascii = false
+		}
+	}
+	switch {
+	case ascii:
+		return "ASCII-only"
+	case len(scripts) <= 1:
+		return "single script"
+	}
+	for _, allowed := range highlyRestrictive {
+		if subset(scripts, allowed) {
+			return "highly restrictive"
+		}
+	}
+	if len(scripts) == 2 && subset([]string{"Latin"}, scripts) &&
+		!subset([]string{"Cyrillic"}, scripts) && !subset([]string{"Greek"}, scripts) {
+		return "moderately restrictive"
+	}
+	return "minimally restrictive"
+}
+
+// subset reports whether every element of a is in b.
+func subset(a, b []string) bool {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			found = found || x == y
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// spoof prints, for each argument, the scripts it uses, its restriction
+// level and the characters that are not in its predominant script, which
+// are the ones that make it mixed-script. It also flags digits from more
+// than one decimal system.
+func spoof(args []string) {
+	for _, a := range args {
+		count := make(map[string]int)
+		var scripts []string
+		zeros := make(map[rune]bool)
+		for _, r := range a {
+			sc := script(r)
+			if unicode.Is(unicode.Nd, r) {
+				zeros[r-rune(digitValue(r))] = true
+			}
+			if sc == "Common" || sc == "Inherited" {
+				continue
+			}
+			if count[sc] == 0 {
+				scripts = append(scripts, sc)
+			}
+			count[sc]++
+		}
+		fmt.Printf("'%s'\n", a)
+		if len(scripts) == 0 {
+			fmt.Printf("\tscripts: none (Common and Inherited only)\n")
+		} else {
+			fmt.Printf("\tscripts: %s\n", strings.Join(scripts, ", "))
+		}
+		fmt.Printf("\trestriction level: %s\n", restrictionLevel(a, scripts))
+		if len(zeros) > 1 {
+			fmt.Printf("\tmixed numbers: digits from %d decimal systems\n", len(zeros))
+		}
+		if len(scripts) < 2 {
+			continue
+		}
+		main := scripts[0]
+		for _, sc := range scripts {
+			if count[sc] > count[main] {
+				main = sc
+			}
+		}
+		for _, r := range a {
+			if sc := script(r); sc != main && sc != "Common" && sc != "Inherited" {
+				fmt.Printf("\tmixing: %#U %s (%s)\n", r, ucd.Name(r), sc)
+			}
+		}
+	}
+}
+
+// digitValue returns the decimal digit value of r from the database.
+func digitValue(r rune) int {
+	fields := strings.Split(lookup(r), ";")
+	if len(fields) < 6 || fields[5] == "" {
+		return 0
+	}
+	return int(fields[5][0] - '0')
+}
@@ -0,0 +1,76 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// The exit statuses of the failures -strict checks for. Errors that stop
+// the command exit with status 2.
+const (
+	exitUnassigned = 3 // A character looked up is unassigned.
+	exitNotFound   = 4 // A search by -g found nothing.
+	exitInvalid    = 5 // Input text is not valid UTF-8.
+)
+
+// strictStatus is the exit status of the first failure found under -strict.
+var strictStatus int
+
+// failStrict reports a failure on standard error and records its exit
+// status, if -strict is set and it is the first; otherwise it does nothing.
+func failStrict(status int, format string, args ...interface{}) {
+	if !*doStrict {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unicode: "+format+"\n", args...)
+	if strictStatus == 0 {
+		strictStatus = status
+	}
+}
+
+// exitStrict exits with the status recorded by failStrict, if any, once
+// the output is complete.
+func exitStrict() {
+	if strictStatus != 0 {
+		exit(strictStatus)
+	}
+}
+
+// exit completes the output and exits with the status.
+func exit(status int) {
+	stdout.Flush()
+	if finishOutput != nil {
+		finishOutput()
+	}
+	os.Exit(status)
+}
+
+// checkUTF8 records under -strict a failure if the text, described by what,
+// is not valid UTF-8.
+func checkUTF8(what, text string) {
+	if utf8.ValidString(text) {
+		return
+	}
+	for i, r := range text {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(text[i:]); size == 1 {
+				failStrict(exitInvalid, "%s: invalid UTF-8 at byte %d", what, i)
+				return
+			}
+		}
+	}
+}
+
+// checkAssigned records under -strict a failure if r is unassigned.
+func checkAssigned(r rune) {
+	if !ucd.Lookup(r).Assigned() {
+		failStrict(exitUnassigned, "%U is unassigned", r)
+	}
+}
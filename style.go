@@ -0,0 +1,116 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// letterlike names the styles of the letters of the Letterlike Symbols
+// block that fill the holes left for them in the Mathematical
+// Alphanumeric Symbols, such as ℎ, the italic h.
+var letterlike = map[rune]string{
+	'ℎ': "italic",
+	'ℬ': "script", 'ℰ': "script", 'ℱ': "script", 'ℋ': "script", 'ℐ': "script", 'ℒ': "script",
+	'ℳ': "script", 'ℛ': "script", 'ℯ': "script", 'ℊ': "script", 'ℴ': "script",
+	'ℭ': "fraktur", 'ℌ': "fraktur", 'ℑ': "fraktur", 'ℜ': "fraktur", 'ℨ': "fraktur",
+	'ℂ': "double-struck", 'ℍ': "double-struck", 'ℕ': "double-struck", 'ℙ': "double-struck",
+	'ℚ': "double-struck", 'ℝ': "double-struck", 'ℤ': "double-struck",
+}
+
+// styles maps the name of each style of -style to the styled form of the
+// ASCII characters it has one for, and unstyled maps each styled character
+// back to ASCII.
+var (
+	styles   map[string]map[rune]rune
+	unstyled map[rune]rune
+)
+
+// loadStyles builds the styles from the database: the mathematical
+// alphanumerics, named for their styles such as bold italic, the
+// fullwidth and circled forms of ASCII, by their decompositions, and the
+// small capitals, by their names.
+func loadStyles() {
+	if styles != nil {
+		return
+	}
+	styles = make(map[string]map[rune]rune)
+	unstyled = make(map[rune]rune)
+	add := func(style string, plain, styled rune) {
+		if styles[style] == nil {
+			styles[style] = make(map[rune]rune)
+		}
+		if _, ok := styles[style][plain]; !ok {
+			styles[style][plain] = styled
+		}
+		unstyled[styled] = plain
+	}
+	ucd.Each(func(e ucd.Entry) {
+		if strings.HasPrefix(e.Name, "LATIN LETTER SMALL CAPITAL ") {
+			if l := strings.TrimPrefix(e.Name, "LATIN LETTER SMALL CAPITAL "); len(l) == 1 {
+				add("small-caps", rune(strings.ToLower(l)[0]), e.Code)
+			}
+			return
+		}
+		if len(e.Decomposition) != 1 || e.Decomposition[0] >= 0x80 {
+			return
+		}
+		plain := e.Decomposition[0]
+		switch e.DecompTag {
+		case "<wide>":
+			add("fullwidth", plain, e.Code)
+		case "<circle>":
+			add("circled", plain, e.Code)
+		case "<font>":
+			if s := letterlike[e.Code]; s != "" {
+				add(s, plain, e.Code)
+				return
+			}
+			name := strings.TrimPrefix(e.Name, "MATHEMATICAL ")
+			for _, kind := range []string{" CAPITAL ", " SMALL ", " DIGIT "} {
+				if i := strings.Index(name, kind); i > 0 && name != e.Name {
+					add(strings.ToLower(strings.ReplaceAll(name[:i], " ", "-")), plain, e.Code)
+				}
+			}
+		}
+	})
+}
+
+// styleNames returns the names of the styles of -style, sorted.
+func styleNames() []string {
+	names := []string{"plain"}
+	for s := range styles {
+		names = append(names, s)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// style prints each argument, or each line of standard input if there are
+// none, with its ASCII letters and digits in the named style, such as
+// bold or fraktur, where the style has them. The style plain turns styled
+// characters back to ASCII.
+func style(name string, args []string) {
+	loadStyles()
+	table := styles[name]
+	if name == "plain" {
+		table = unstyled
+	}
+	if table == nil {
+		fatalf("-style: unknown style %q; want one of %s", name, strings.Join(styleNames(), ", "))
+	}
+	for _, a := range argsOrStdin(args) {
+		fmt.Println(strings.Map(func(r rune) rune {
+			if s, ok := table[r]; ok {
+				return s
+			}
+			return r
+		}, a))
+	}
+}
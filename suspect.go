@@ -0,0 +1,163 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// suspectContext is the number of characters of context shown on each
+// side of a suspicious one.
+const suspectContext = 30
+
+// suspicion returns why r is suspicious in text, or "": it is invisible,
+// or a space other than U+0020, or a control, or otherwise likely to be
+// there by mistake. prev and next are the characters around r, or -1.
+// Joiners and variation selectors that belong to emoji are not suspicious.
+func suspicion(prev, r, next rune) string {
+	pictographic := isEmojiProp("Extended_Pictographic")
+	switch {
+	case r == '\t' || r == '\n':
+		return ""
+	case r == '\r':
+		if next == '\n' {
+			return ""
+		}
+		return "carriage return without line feed"
+	case r == utf8.RuneError:
+		return "replacement character, left by an earlier decoding error"
+	case unicode.Is(unicode.Bidi_Control, r):
+		return "bidi control"
+	case r == 0xAD:
+		return "soft hyphen"
+	case r == 0xFEFF:
+		return "byte order mark, or zero width no-break space"
+	case r == 0x200D && prev >= 0 && next >= 0 && pictographic(prev) && pictographic(next):
+		return ""
+	case unicode.Is(unicode.Variation_Selector, r) && prev >= 0 && hasVariation(prev, r):
+		return ""
+	case isDefaultIgnorable(r):
+		return "invisible"
+	case unicode.Is(unicode.Zs, r) && r != ' ':
+		return "space other than U+0020"
+	case unicode.In(r, unicode.Zl, unicode.Zp):
+		return "line or paragraph separator"
+	case unicode.IsControl(r):
+		return "control"
+	case unicode.Is(unicode.Noncharacter_Code_Point, r):
+		return "noncharacter"
+	case unicode.Is(unicode.Co, r):
+		return "private use"
+	case !ucd.Lookup(r).Assigned():
+		return "unassigned"
+	}
+	return ""
+}
+
+// hasVariation reports whether the variation sequence of r and the
+// selector vs is defined.
+func hasVariation(r, vs rune) bool {
+	for _, v := range variations(r) {
+		if v.selector == vs {
+			return true
+		}
+	}
+	return false
+}
+
+// suspect prints each suspicious character, and each invalid byte of
+// UTF-8, in the text of the named files, or of standard input if there
+// are none: its position as file:line:column, counting characters, why it
+// is suspect, and its line with suspicious characters shown as their code
+// points, such as [200B], and a caret below this one.
+func suspect(files []string) {
+	eachInput(files, func(_ int, name string, data []byte) {
+		for n, line := range strings.Split(inputText(data), "\n") {
+			suspectLine(fmt.Sprintf("%s:%d", name, n+1), line)
+		}
+	})
+}
+
+// A lineChar is a character of a line, or an invalid byte, with the
+// form in which it is shown and, if it is suspect, why.
+type lineChar struct {
+	r      rune // utf8.RuneError with size 1 for an invalid byte.
+	off    int  // The offset in the line.
+	size   int
+	shown  string
+	reason string
+}
+
+// suspectLine reports the suspicious characters of the line, whose
+// position in its file is pos. The line ends before its newline, so a
+// carriage return at its end precedes one.
+func suspectLine(pos, line string) {
+	var chars []lineChar
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		chars = append(chars, lineChar{r: r, off: i, size: size})
+		i += size
+	}
+	for i := range chars {
+		c := &chars[i]
+		prev, next := rune(-1), rune('\n')
+		if i > 0 {
+			prev = chars[i-1].r
+		}
+		if i+1 < len(chars) {
+			next = chars[i+1].r
+		}
+		switch {
+		case c.r == utf8.RuneError && c.size == 1:
+			c.reason = "invalid UTF-8"
+			c.shown = fmt.Sprintf("[x%02X]", line[c.off])
+		case c.r == '\t':
+			c.shown = " "
+		default:
+			c.reason = suspicion(prev, c.r, next)
+			c.shown = string(c.r)
+			if c.reason != "" || !unicode.IsPrint(c.r) && c.r != ' ' {
+				c.shown = fmt.Sprintf("[%04X]", c.r)
+			}
+		}
+	}
+	for i, c := range chars {
+		if c.reason == "" {
+			continue
+		}
+		desc := c.reason
+		if c.size > 1 || c.r != utf8.RuneError {
+			desc = fmt.Sprintf("%U %s: %s", c.r, strings.ToLower(ucd.Name(c.r)), c.reason)
+		}
+		fmt.Printf("%s:%d: %s\n", pos, i+1, desc)
+		lo, hi := i-suspectContext, i+suspectContext+1
+		var context strings.Builder
+		if lo <= 0 {
+			lo = 0
+		} else {
+			context.WriteString("…")
+		}
+		if hi > len(chars) {
+			hi = len(chars)
+		}
+		for _, d := range chars[lo:i] {
+			context.WriteString(d.shown)
+		}
+		caret := uniseg.StringWidth(context.String())
+		for _, d := range chars[i:hi] {
+			context.WriteString(d.shown)
+		}
+		if hi < len(chars) {
+			context.WriteString("…")
+		}
+		fmt.Printf("\t%s\n\t%s%s\n", context.String(), strings.Repeat(" ", caret), strings.Repeat("^", uniseg.StringWidth(c.shown)))
+	}
+}
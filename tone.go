@@ -0,0 +1,103 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const lightSkinTone = 0x1F3FB // EMOJI MODIFIER FITZPATRICK TYPE-1-2
+
+// skinTones holds the CLDR names of the five emoji modifiers, in order.
+var skinTones = []string{
+	"light skin tone",
+	"medium-light skin tone",
+	"medium skin tone",
+	"medium-dark skin tone",
+	"dark skin tone",
+}
+
+func isSkinTone(r rune) bool {
+	return lightSkinTone <= r && r < lightSkinTone+rune(len(skinTones))
+}
+
+// tone implements the -tone flag. The value is a tone from 1 (light) to 5
+// (dark), which is applied to each argument; "strip", which removes the tones
+// from each argument; or "show", which reports the tones of each argument.
+func tone(value string, args []string) {
+	loadEmojiTest()
+	var fn func(string) string
+	switch value {
+	case "strip":
+		fn = stripTone
+	case "show":
+		fn = showTone
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > len(skinTones) {
+			fatalf("-tone: want 1 to %d, strip or show; have %q", len(skinTones), value)
+		}
+		fn = func(s string) string { return applyTone(s, lightSkinTone+rune(n-1)) }
+	}
+	for _, a := range args {
+		fmt.Println(fn(a))
+	}
+}
+
+// applyTone returns s with the modifier m following each character that
+// takes a skin tone, replacing any tone already present. The emoji
+// presentation selector is dropped after a toned character, as in the
+// recommended (RGI) modifier sequences.
+func applyTone(s string, m rune) string {
+	isBase := lookupProp("Emoji_Modifier_Base")
+	var b strings.Builder
+	toned := false
+	for _, r := range s {
+		switch {
+		case isSkinTone(r), r == 0xFE0F && toned:
+			continue
+		}
+		b.WriteRune(r)
+		toned = isBase(r)
+		if toned {
+			b.WriteRune(m)
+		}
+	}
+	return b.String()
+}
+
+// stripTone returns s without its skin tones. If the result is an emoji
+// that is not fully qualified, the fully-qualified form is returned instead.
+func stripTone(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if isSkinTone(r) {
+			return -1
+		}
+		return r
+	}, s)
+	bare := strings.ReplaceAll(s, "\uFE0F", "")
+	for _, e := range emojiList {
+		if e.status == "fully-qualified" && strings.ReplaceAll(e.text, "\uFE0F", "") == bare {
+			return e.text
+		}
+	}
+	return s
+}
+
+// showTone returns s followed by the names of the skin tones it carries.
+func showTone(s string) string {
+	var tones []string
+	for _, r := range s {
+		if isSkinTone(r) {
+			tones = append(tones, skinTones[r-lightSkinTone])
+		}
+	}
+	if tones == nil {
+		return s + " no skin tone"
+	}
+	return s + " " + strings.Join(tones, ", ")
+}
@@ -0,0 +1,132 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// A tortureCase is a string that exercises a hard case for text handling.
+type tortureCase struct {
+	name string
+	text string
+}
+
+// tortureCases returns the strings of -torture, built from the database.
+func tortureCases() []tortureCase {
+	var cases []tortureCase
+	add := func(name, text string) {
+		cases = append(cases, tortureCase{name, text})
+	}
+
+	// The emoji sequence with the most code points, a single cluster.
+	loadEmojiTest()
+	longest := ""
+	for _, e := range emojiList {
+		if e.status == "fully-qualified" && utf8.RuneCountInString(e.text) > utf8.RuneCountInString(longest) {
+			longest = e.text
+		}
+	}
+	add("longest emoji cluster", longest)
+
+	// A base with every combining mark above from Combining Diacritical
+	// Marks, and one with every mark of the block, stacked in one cluster.
+	var above, all strings.Builder
+	for r := rune(0x300); r <= 0x36F; r++ {
+		e := ucd.Lookup(r)
+		if e.Category != "Mn" {
+			continue
+		}
+		if e.CombiningClass == 230 {
+			above.WriteRune(r)
+		}
+		all.WriteRune(r)
+	}
+	add("combining pileup above", "a"+above.String())
+	add("combining pileup", "e"+all.String()+"x")
+	add("combining marks without base", string([]rune(all.String())[:4]))
+
+	// Bidi formatting characters, unterminated and overflowing.
+	var controls strings.Builder
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if unicode.Is(unicode.Bidi_Control, r) {
+			controls.WriteRune(r)
+		}
+	}
+	add("bidi controls", "a"+controls.String()+"b")
+	add("unterminated override", "abc\u202Edef")
+	add("unterminated isolate", "abc\u2067def")
+	add("unmatched pop", "abc\u202Cdef\u2069")
+	add("embeddings beyond max depth", strings.Repeat("\u202B\u202A", 70)+"x")
+	add("mixed direction", "abc \u05D0\u05D1\u05D2 123 \u0639\u0631\u0628\u064A def")
+
+	// The noncharacters: U+FDD0..U+FDEF and the last two of each plane.
+	var nonchars strings.Builder
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if unicode.Is(unicode.Noncharacter_Code_Point, r) {
+			nonchars.WriteRune(r)
+		}
+	}
+	add("noncharacters", nonchars.String())
+
+	// Surrogates encoded in UTF-8 as if they were characters, which is
+	// invalid: alone, reversed, and as a pair (CESU-8) for U+1F600.
+	add("unpaired high surrogate", "a\xed\xa0\x80b")
+	add("unpaired low surrogate", "a\xed\xb0\x80b")
+	add("reversed surrogate pair", "\xed\xb8\x80\xed\xa0\xbd")
+	add("CESU-8 surrogate pair", "\xed\xa0\xbd\xed\xb8\x80")
+	add("overlong encodings", "\xc0\xaf\xe0\x80\xaf\xf0\x80\x80\xaf")
+	add("beyond U+10FFFF", "\xf4\x90\x80\x80")
+	add("truncated sequence", "\xe2\x82")
+
+	// The zero-width characters by name, and joiners out of place.
+	var zw strings.Builder
+	ucd.Each(func(e ucd.Entry) {
+		if strings.HasPrefix(e.Name, "ZERO WIDTH") {
+			zw.WriteRune(e.Code)
+		}
+	})
+	add("zero width characters", "a"+zw.String()+"b")
+	add("leading and trailing ZWJ", "\u200D\U0001F44D\u200D")
+	add("doubled ZWJ", "\U0001F469\u200D\u200D\U0001F469")
+	add("ZWJ between letters", "a\u200Db\u200Cc")
+	add("BOM mid-text", "ab\uFEFFcd")
+
+	// The first and last code points of each plane, and the edges of the
+	// UTF-8 encoding lengths.
+	var edges strings.Builder
+	for p := rune(0); p <= 16; p++ {
+		edges.WriteRune(p << 16)
+		edges.WriteRune(p<<16 | 0xFFFF)
+	}
+	add("plane boundaries", edges.String())
+	add("UTF-8 length boundaries", "\u007F\u0080\u07FF\u0800\uFFFF\U00010000\U0010FFFF")
+	add("NUL and controls", "a\x00b\x1b[31mc\u0085d\u2028e\u2029f")
+	return cases
+}
+
+// torture prints the strings of -torture, each with its name and Go
+// quoted form, or with -t as raw text, one per line.
+func torture() {
+	for _, c := range tortureCases() {
+		if *doText {
+			fmt.Println(c.text)
+			continue
+		}
+		desc := fmt.Sprintf("%d bytes", len(c.text))
+		if utf8.ValidString(c.text) {
+			desc += fmt.Sprintf(", %d runes, %d clusters", utf8.RuneCountInString(c.text), uniseg.GraphemeClusterCount(c.text))
+		} else {
+			desc += ", invalid UTF-8"
+		}
+		fmt.Printf("%s (%s):\n\t%+q\n", c.name, desc, c.text)
+	}
+}
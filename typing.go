@@ -0,0 +1,222 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// The hints for typing a character come from the X11 compose table, the
+// digraph table of Vim's documentation, and a table of LaTeX commands
+// with the LaTeX accents applied to the decompositions of characters.
+
+//go:generate sh -c "cp /usr/share/X11/locale/en_US.UTF-8/Compose Compose.txt"
+//go:generate sh -c "curl https://raw.githubusercontent.com/vim/vim/master/runtime/doc/digraph.txt >digraph.txt"
+var (
+	//go:embed Compose.txt
+	composeTxt string
+	//go:embed digraph.txt
+	digraphTxt  string
+	composeKeys map[rune]string // The shortest compose sequence for a rune, such as 'e.
+	digraphs    map[rune]string // The first Vim digraph for a rune, such as e'.
+)
+
+// keysyms maps the names of the X11 keysyms for ASCII punctuation to
+// their characters. Letters and digits are their own names.
+var keysyms = map[string]string{
+	"space": "␣", "exclam": "!", "quotedbl": `"`, "numbersign": "#",
+	"dollar": "$", "percent": "%", "ampersand": "&", "apostrophe": "'",
+	"parenleft": "(", "parenright": ")", "asterisk": "*", "plus": "+",
+	"comma": ",", "minus": "-", "period": ".", "slash": "/",
+	"colon": ":", "semicolon": ";", "less": "<", "equal": "=",
+	"greater": ">", "question": "?", "at": "@", "bracketleft": "[",
+	"backslash": `\`, "bracketright": "]", "asciicircum": "^",
+	"underscore": "_", "grave": "`", "braceleft": "{", "bar": "|",
+	"braceright": "}", "asciitilde": "~",
+}
+
+var composeRE = regexp.MustCompile(`^<Multi_key>((?:\s*<\w+>)+)\s*:\s*"((?:[^"\\]|\\.)+)"`)
+
+// loadCompose reads the sequences of the compose table that begin with
+// the Compose key and continue with ASCII keys only.
+func loadCompose() {
+	if composeKeys != nil {
+		return
+	}
+	composeKeys = make(map[rune]string)
+lines:
+	for _, line := range splitLines(composeTxt) {
+		m := composeRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		text, err := strconv.Unquote(`"` + m[2] + `"`)
+		if err != nil || len([]rune(text)) != 1 {
+			continue
+		}
+		var keys strings.Builder
+		for _, k := range strings.Fields(m[1]) {
+			k = strings.Trim(k, "<>")
+			switch {
+			case len(k) == 1:
+				keys.WriteString(k)
+			case keysyms[k] != "":
+				keys.WriteString(keysyms[k])
+			default:
+				continue lines
+			}
+		}
+		r := []rune(text)[0]
+		if old, ok := composeKeys[r]; !ok || len([]rune(keys.String())) < len([]rune(old)) {
+			composeKeys[r] = keys.String()
+		}
+	}
+}
+
+var digraphRE = regexp.MustCompile(`^[^\t]*\t(\S\S)\t(?:0x)?([0-9a-fA-F]+)\t *[0-9]+\t`)
+
+// loadDigraphs reads the digraph table of Vim's documentation, whose
+// lines hold a character, its digraph, and its code in hex and decimal.
+func loadDigraphs() {
+	if digraphs != nil {
+		return
+	}
+	digraphs = make(map[rune]string)
+	for _, line := range splitLines(digraphTxt) {
+		if m := digraphRE.FindStringSubmatch(line); m != nil {
+			r := parseRune(m[2])
+			if _, ok := digraphs[r]; !ok {
+				digraphs[r] = m[1]
+			}
+		}
+	}
+}
+
+// latexAccents maps combining marks to the LaTeX commands that accent a letter.
+var latexAccents = map[rune]string{
+	0x0300: "\\`", 0x0301: `\'`, 0x0302: `\^`, 0x0303: `\~`, 0x0304: `\=`,
+	0x0306: `\u`, 0x0307: `\.`, 0x0308: `\"`, 0x030A: `\r`, 0x030B: `\H`,
+	0x030C: `\v`, 0x0323: `\d`, 0x0327: `\c`, 0x0328: `\k`, 0x0331: `\b`,
+}
+
+// latexCommands maps characters to the LaTeX commands, in text or math
+// mode, that typeset them.
+var latexCommands = map[rune]string{
+	// Text.
+	0x00A1: `\textexclamdown`, 0x00A3: `\pounds`, 0x00A7: `\S`, 0x00A9: `\copyright`,
+	0x00AE: `\textregistered`, 0x00B0: `\textdegree`, 0x00B6: `\P`, 0x00B7: `\textperiodcentered`,
+	0x00BF: `\textquestiondown`, 0x00C5: `\AA`, 0x00C6: `\AE`, 0x00D8: `\O`,
+	0x00DF: `\ss`, 0x00E5: `\aa`, 0x00E6: `\ae`, 0x00F8: `\o`,
+	0x0131: `\i`, 0x0141: `\L`, 0x0142: `\l`, 0x0152: `\OE`,
+	0x0153: `\oe`, 0x0237: `\j`, 0x2013: `\textendash`, 0x2014: `\textemdash`,
+	0x2018: `\textquoteleft`, 0x2019: `\textquoteright`, 0x201C: `\textquotedblleft`, 0x201D: `\textquotedblright`,
+	0x2020: `\dag`, 0x2021: `\ddag`, 0x2022: `\textbullet`, 0x2026: `\ldots`,
+	0x20AC: `\texteuro`, 0x2122: `\texttrademark`,
+
+	// Greek.
+	0x0393: `\Gamma`, 0x0394: `\Delta`, 0x0398: `\Theta`, 0x039B: `\Lambda`,
+	0x039E: `\Xi`, 0x03A0: `\Pi`, 0x03A3: `\Sigma`, 0x03A5: `\Upsilon`,
+	0x03A6: `\Phi`, 0x03A8: `\Psi`, 0x03A9: `\Omega`, 0x03B1: `\alpha`,
+	0x03B2: `\beta`, 0x03B3: `\gamma`, 0x03B4: `\delta`, 0x03B5: `\varepsilon`,
+	0x03B6: `\zeta`, 0x03B7: `\eta`, 0x03B8: `\theta`, 0x03B9: `\iota`,
+	0x03BA: `\kappa`, 0x03BB: `\lambda`, 0x03BC: `\mu`, 0x03BD: `\nu`,
+	0x03BE: `\xi`, 0x03C0: `\pi`, 0x03C1: `\rho`, 0x03C2: `\varsigma`,
+	0x03C3: `\sigma`, 0x03C4: `\tau`, 0x03C5: `\upsilon`, 0x03C6: `\varphi`,
+	0x03C7: `\chi`, 0x03C8: `\psi`, 0x03C9: `\omega`, 0x03D1: `\vartheta`,
+	0x03D5: `\phi`, 0x03D6: `\varpi`, 0x03F1: `\varrho`, 0x03F5: `\epsilon`,
+
+	// Mathematics.
+	0x00AC: `\neg`, 0x00B1: `\pm`, 0x00D7: `\times`, 0x00F7: `\div`,
+	0x2032: `\prime`, 0x2102: `\mathbb{C}`, 0x210F: `\hbar`, 0x2111: `\Im`,
+	0x2113: `\ell`, 0x2115: `\mathbb{N}`, 0x2118: `\wp`, 0x211A: `\mathbb{Q}`,
+	0x211C: `\Re`, 0x211D: `\mathbb{R}`, 0x2124: `\mathbb{Z}`, 0x2135: `\aleph`,
+	0x2200: `\forall`, 0x2202: `\partial`, 0x2203: `\exists`, 0x2205: `\emptyset`,
+	0x2207: `\nabla`, 0x2208: `\in`, 0x2209: `\notin`, 0x220B: `\ni`,
+	0x220F: `\prod`, 0x2210: `\coprod`, 0x2211: `\sum`, 0x2213: `\mp`,
+	0x2216: `\setminus`, 0x2217: `\ast`, 0x2218: `\circ`, 0x2219: `\bullet`,
+	0x221A: `\surd`, 0x221D: `\propto`, 0x221E: `\infty`, 0x2220: `\angle`,
+	0x2223: `\mid`, 0x2225: `\parallel`, 0x2227: `\wedge`, 0x2228: `\vee`,
+	0x2229: `\cap`, 0x222A: `\cup`, 0x222B: `\int`, 0x222E: `\oint`,
+	0x223C: `\sim`, 0x2243: `\simeq`, 0x2245: `\cong`, 0x2248: `\approx`,
+	0x2260: `\neq`, 0x2261: `\equiv`, 0x2264: `\leq`, 0x2265: `\geq`,
+	0x226A: `\ll`, 0x226B: `\gg`, 0x227A: `\prec`, 0x227B: `\succ`,
+	0x2282: `\subset`, 0x2283: `\supset`, 0x2286: `\subseteq`, 0x2287: `\supseteq`,
+	0x228E: `\uplus`, 0x2291: `\sqsubseteq`, 0x2292: `\sqsupseteq`, 0x2293: `\sqcap`,
+	0x2294: `\sqcup`, 0x2295: `\oplus`, 0x2296: `\ominus`, 0x2297: `\otimes`,
+	0x2298: `\oslash`, 0x2299: `\odot`, 0x22A2: `\vdash`, 0x22A3: `\dashv`,
+	0x22A4: `\top`, 0x22A5: `\bot`, 0x22A8: `\models`, 0x22C4: `\diamond`,
+	0x22C5: `\cdot`, 0x22C6: `\star`, 0x22C8: `\bowtie`, 0x22EE: `\vdots`,
+	0x22EF: `\cdots`, 0x22F1: `\ddots`, 0x2308: `\lceil`, 0x2309: `\rceil`,
+	0x230A: `\lfloor`, 0x230B: `\rfloor`, 0x25B3: `\triangle`, 0x2660: `\spadesuit`,
+	0x2661: `\heartsuit`, 0x2662: `\diamondsuit`, 0x2663: `\clubsuit`, 0x266D: `\flat`,
+	0x266E: `\natural`, 0x266F: `\sharp`, 0x27E8: `\langle`, 0x27E9: `\rangle`,
+
+	// Arrows.
+	0x2190: `\leftarrow`, 0x2191: `\uparrow`, 0x2192: `\rightarrow`, 0x2193: `\downarrow`,
+	0x2194: `\leftrightarrow`, 0x2195: `\updownarrow`, 0x2196: `\nwarrow`, 0x2197: `\nearrow`,
+	0x2198: `\searrow`, 0x2199: `\swarrow`, 0x21A6: `\mapsto`, 0x21A9: `\hookleftarrow`,
+	0x21AA: `\hookrightarrow`, 0x21BC: `\leftharpoonup`, 0x21C0: `\rightharpoonup`, 0x21D0: `\Leftarrow`,
+	0x21D1: `\Uparrow`, 0x21D2: `\Rightarrow`, 0x21D3: `\Downarrow`, 0x21D4: `\Leftrightarrow`,
+	0x27F5: `\longleftarrow`, 0x27F6: `\longrightarrow`, 0x27F9: `\Longrightarrow`, 0x27FC: `\longmapsto`,
+}
+
+// latex returns the LaTeX for r: a command from the table, or an ASCII
+// letter with one or more accents, such as \'e, \c{c} or \'{\^e}.
+func latex(r rune) string {
+	if cmd, ok := latexCommands[r]; ok {
+		return cmd
+	}
+	d := []rune(norm.NFD.String(string(r)))
+	if len(d) < 2 {
+		return ""
+	}
+	var s string
+	switch c := d[0]; {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		s = string(c)
+	default:
+		return ""
+	}
+	for _, m := range d[1:] {
+		acc, ok := latexAccents[m]
+		if !ok {
+			return ""
+		}
+		// Accents named by letters, and accents of accented letters, need braces.
+		if 'a' <= acc[1] && acc[1] <= 'z' || 'A' <= acc[1] && acc[1] <= 'Z' || len(s) > 1 {
+			s = acc + "{" + s + "}"
+		} else {
+			s = acc + s
+		}
+	}
+	return s
+}
+
+// typingHints returns the ways to type r with the compose key, as a Vim
+// digraph, and in LaTeX, such as "compose 'e", "digraph e'" and "LaTeX \'e".
+// There are none for ASCII.
+func typingHints(r rune) []string {
+	if r < 0x80 {
+		return nil
+	}
+	loadCompose()
+	loadDigraphs()
+	var hints []string
+	if k, ok := composeKeys[r]; ok {
+		hints = append(hints, "compose "+k)
+	}
+	if d, ok := digraphs[r]; ok {
+		hints = append(hints, "digraph "+d)
+	}
+	if t := latex(r); t != "" {
+		hints = append(hints, "LaTeX "+t)
+	}
+	return hints
+}
@@ -0,0 +1,90 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ucd
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	//go:embed NameAliases.txt.gz
+	nameAliasesGz  []byte
+	nameAliasesTxt string // Set by LoadNameAliases, or else decompressed from nameAliasesGz.
+	nameAliases    map[rune][]Alias
+	aliasOnce      sync.Once
+)
+
+// An Alias is a formal name alias of a character. Its Kind is one of
+// correction, control, alternate, figment or abbreviation.
+type Alias struct {
+	Name, Kind string
+}
+
+func (a Alias) String() string {
+	return a.Name + " (" + a.Kind + ")"
+}
+
+// Aliases returns the formal name aliases of r, in the order of the database.
+func Aliases(r rune) []Alias {
+	aliasOnce.Do(loadAliases)
+	return nameAliases[r]
+}
+
+// LoadNameAliases replaces the embedded NameAliases.txt with data. Like
+// LoadUnicodeData, it must be called before the other functions of the
+// package, and returns an error if data is malformed.
+func LoadNameAliases(data string) (err error) {
+	loaded := false
+	aliasOnce.Do(func() {
+		loaded = true
+		defer func() {
+			if e := recover(); e != nil {
+				err = fmt.Errorf("%v", e)
+				nameAliases = make(map[rune][]Alias)
+			}
+		}()
+		nameAliasesTxt = strings.ReplaceAll(data, "\r\n", "\n")
+		loadAliases()
+	})
+	if !loaded {
+		return errors.New("ucd: aliases already loaded")
+	}
+	return err
+}
+
+func loadAliases() {
+	if nameAliasesTxt == "" {
+		nameAliasesTxt = gunzip(nameAliasesGz)
+	}
+	nameAliases = make(map[rune][]Alias)
+	for i, line := range splitLines(nameAliasesTxt) {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		f := strings.Split(line, ";")
+		if len(f) != 3 {
+			panic(fmt.Sprintf("ucd: malformed NameAliases.txt: line %d", i+1))
+		}
+		r := parseRune(f[0])
+		nameAliases[r] = append(nameAliases[r], Alias{f[1], f[2]})
+	}
+}
+
+// Name returns the best name for r: its formal name, or for characters
+// such as controls, which have only a label like <control>, their first
+// alias. It returns "" if r is unassigned.
+func Name(r rune) string {
+	formal := Lookup(r).Name
+	if len(formal) > 0 && formal[0] == '<' {
+		if a := Aliases(r); len(a) > 0 {
+			return a[0].Name
+		}
+	}
+	return formal
+}
@@ -0,0 +1,133 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ucd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+)
+
+// A compiled database holds, after a header, the code point of each line
+// and the offset of each line in the text, as little-endian 32-bit numbers,
+// and then the text, the lines of UnicodeData.txt. It is read without
+// parsing, and without copying if the data is mapped from a file.
+//
+//	magic   "ucd compiled 1\n"
+//	tag     length (32 bits) and bytes
+//	n       number of lines (32 bits)
+//	codes   n code points
+//	offsets n+1 offsets, relative to the text, of the lines and the end
+//	text    the lines, each ending in a newline
+
+const compiledMagic = "ucd compiled 1\n"
+
+// Compile writes the database in compiled form, labeled with tag, which
+// identifies the source of the data, such as a file and its time of
+// modification, for LoadCompiled to check.
+func Compile(w io.Writer, tag string) error {
+	load()
+	b := bufio.NewWriter(w)
+	b.WriteString(compiledMagic)
+	put := func(n int) {
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], uint32(n))
+		b.Write(buf[:])
+	}
+	put(len(tag))
+	b.WriteString(tag)
+	put(len(unicodeLines))
+	for _, r := range lineCodes {
+		put(int(r))
+	}
+	off := 0
+	for _, line := range unicodeLines {
+		put(off)
+		off += len(line) + 1
+	}
+	put(off)
+	for _, line := range unicodeLines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.Flush()
+}
+
+// LoadCompiled replaces the embedded database with data, written by Compile
+// with the same tag. Like LoadUnicodeData, it must be called before the
+// other functions of the package. The database refers to data, which must
+// not be modified afterwards.
+func LoadCompiled(data []byte, tag string) (err error) {
+	if !bytes.HasPrefix(data, []byte(compiledMagic)) {
+		return errors.New("ucd: not a compiled database")
+	}
+	p := data[len(compiledMagic):]
+	malformed := errors.New("ucd: malformed compiled database")
+	get := func() int {
+		if len(p) < 4 {
+			err = malformed
+			return 0
+		}
+		n := binary.LittleEndian.Uint32(p)
+		p = p[4:]
+		return int(n)
+	}
+	n := get()
+	if err != nil || n > len(p) {
+		return malformed
+	}
+	if string(p[:n]) != tag {
+		return fmt.Errorf("ucd: compiled database is out of date")
+	}
+	p = p[n:]
+	n = get()
+	if err != nil || 8*n+4 > len(p) {
+		return malformed
+	}
+	codes := make([]rune, n)
+	for i := range codes {
+		codes[i] = rune(get())
+	}
+	offsets := make([]int, n+1)
+	for i := range offsets {
+		offsets[i] = get()
+	}
+	if offsets[n] != len(p) {
+		return malformed
+	}
+	text := bytesToString(p)
+	lines := make([]string, n)
+	for i := range lines {
+		lo, hi := offsets[i], offsets[i+1]-1
+		if lo > hi || hi >= len(text) {
+			return malformed
+		}
+		lines[i] = text[lo:hi]
+	}
+	loaded := false
+	loadOnce.Do(func() {
+		loaded = true
+		unicodeLines, lineCodes = lines, codes
+		loadEntries()
+	})
+	if !loaded {
+		return errors.New("ucd: database already loaded")
+	}
+	return nil
+}
+
+// bytesToString returns the bytes of b as a string without copying them.
+func bytesToString(b []byte) string {
+	var s string
+	h := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	h.Data = (*reflect.SliceHeader)(unsafe.Pointer(&b)).Data
+	h.Len = len(b)
+	return s
+}
@@ -0,0 +1,31 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ucd
+
+// Hangul syllable names are derived from their jamo by the algorithm of
+// section 3.12 of the Unicode Standard.
+
+const (
+	hangulBase   = 0xAC00
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulCount  = 19 * hangulNCount
+)
+
+var (
+	jamoL = [...]string{"G", "GG", "N", "D", "DD", "R", "M", "B", "BB", "S", "SS", "", "J", "JJ", "C", "K", "T", "P", "H"}
+	jamoV = [...]string{"A", "AE", "YA", "YAE", "EO", "E", "YEO", "YE", "O", "WA", "WAE", "OE", "YO", "U", "WEO", "WE", "WI", "YU", "EU", "YI", "I"}
+	jamoT = [...]string{"", "G", "GG", "GS", "N", "NJ", "NH", "D", "L", "LG", "LM", "LB", "LS", "LT", "LP", "LH", "M", "B", "BS", "S", "SS", "NG", "J", "C", "K", "T", "P", "H"}
+)
+
+// hangulName returns the name of the Hangul syllable r, such as HANGUL SYLLABLE GAG.
+func hangulName(r rune) string {
+	s := int(r - hangulBase)
+	if s < 0 || s >= hangulCount {
+		return ""
+	}
+	return "HANGUL SYLLABLE " + jamoL[s/hangulNCount] + jamoV[s%hangulNCount/hangulTCount] + jamoT[s%hangulTCount]
+}
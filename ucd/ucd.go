@@ -0,0 +1,477 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ucd provides the character data of the Unicode Character
+// Database, UnicodeData.txt and NameAliases.txt, as used by the unicode
+// command. The data is embedded, so programs need no files at run time,
+// but another UnicodeData.txt may be loaded in its place.
+// The functions are safe for concurrent use.
+package ucd // import "robpike.io/cmd/unicode/ucd"
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the version of Unicode of the embedded data, here and in the
+// unicode command. Its files are fetched by gen.go in the parent directory.
+const Version = "15.0.0"
+
+var (
+	//go:embed UnicodeData.txt.gz
+	unicodeDataGz []byte // Decompressed on first use by loadEntries.
+	unicodeLines  []string
+)
+
+// gunzip returns the decompressed contents of data, which must be valid.
+func gunzip(data []byte) string {
+	z, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		panic("ucd: " + err.Error())
+	}
+	text, err := io.ReadAll(z)
+	if err != nil {
+		panic("ucd: " + err.Error())
+	}
+	return string(text)
+}
+
+// An Entry holds the fields of a line of UnicodeData.txt, as described in
+// section 4.4 of UAX #44.
+type Entry struct {
+	Code           rune
+	Name           string // Characters in ranges are given their own names, such as CJK UNIFIED IDEOGRAPH-4E00.
+	Category       string // General category, such as Lu.
+	CombiningClass uint8
+	BidiClass      string
+	DecompTag      string // Decomposition type, such as <compat>, or "" for a canonical decomposition.
+	Decomposition  []rune
+	Decimal        int    // Decimal digit value, or -1 if none.
+	Digit          int    // Digit value, or -1 if none.
+	Numeric        string // Numeric value, such as 1/4, or "" if none.
+	BidiMirrored   bool
+	OldName        string // Unicode 1.0 name.
+	Comment        string // ISO 10646 comment, now always empty.
+	Upper          rune   // Simple uppercase mapping, or 0 if none.
+	Lower          rune   // Simple lowercase mapping, or 0 if none.
+	Title          rune   // Simple titlecase mapping, or 0 if none.
+}
+
+// Assigned reports whether the entry is in the database.
+func (e Entry) Assigned() bool {
+	return e.Name != ""
+}
+
+// String returns the entry in the form of its line of UnicodeData.txt,
+// without the code point.
+func (e Entry) String() string {
+	if !e.Assigned() {
+		return ""
+	}
+	f := []string{e.Name, e.Category, strconv.Itoa(int(e.CombiningClass)), e.BidiClass}
+	var decomp []string
+	if e.DecompTag != "" {
+		decomp = append(decomp, e.DecompTag)
+	}
+	for _, r := range e.Decomposition {
+		decomp = append(decomp, fmt.Sprintf("%04X", r))
+	}
+	f = append(f, strings.Join(decomp, " "), value(e.Decimal), value(e.Digit), e.Numeric)
+	if e.BidiMirrored {
+		f = append(f, "Y")
+	} else {
+		f = append(f, "N")
+	}
+	f = append(f, e.OldName, e.Comment, mapping(e.Upper), mapping(e.Lower), mapping(e.Title))
+	return strings.Join(f, ";")
+}
+
+func value(n int) string {
+	if n < 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func mapping(r rune) string {
+	if r == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%04X", r)
+}
+
+// parseEntry parses the line of the database for r, minus the code point.
+func parseEntry(r rune, data string) Entry {
+	f := strings.Split(data, ";")
+	if len(f) != 14 {
+		panic(fmt.Sprintf("ucd: malformed UnicodeData.txt entry for %U", r))
+	}
+	e := Entry{
+		Code:         r,
+		Name:         f[0],
+		Category:     f[1],
+		BidiClass:    f[3],
+		Decimal:      number(f[5]),
+		Digit:        number(f[6]),
+		Numeric:      f[7],
+		BidiMirrored: f[8] == "Y",
+		OldName:      f[9],
+		Comment:      f[10],
+		Upper:        parseMapping(f[11]),
+		Lower:        parseMapping(f[12]),
+		Title:        parseMapping(f[13]),
+	}
+	ccc, _ := strconv.Atoi(f[2])
+	e.CombiningClass = uint8(ccc)
+	for _, d := range strings.Fields(f[4]) {
+		if d[0] == '<' {
+			e.DecompTag = d
+			continue
+		}
+		e.Decomposition = append(e.Decomposition, parseRune(d))
+	}
+	return e
+}
+
+func number(s string) int {
+	if s == "" {
+		return -1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func parseMapping(s string) rune {
+	if s == "" {
+		return 0
+	}
+	return parseRune(s)
+}
+
+func parseRune(s string) rune {
+	r, err := strconv.ParseInt(s, 16, 22)
+	if err != nil {
+		panic("ucd: " + err.Error())
+	}
+	return rune(r)
+}
+
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	// We get an empty final line; drop it.
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func runeOfLine(i int, line string) (r rune, tab int) {
+	tab = strings.IndexAny(line, "\t;")
+	if tab < 0 {
+		panic(fmt.Sprintf("ucd: malformed database: line %d", i))
+	}
+	return parseRune(line[0:tab]), tab
+}
+
+// lineCodes holds the code point of each line of unicodeLines, in
+// increasing order. It is built on demand by load, which reads only the
+// code points; the entries are parsed as they are needed.
+var (
+	lineCodes []rune
+	loadOnce  sync.Once
+)
+
+// A dbRange is a range of runes that the database records by a pair of
+// lines with names such as <CJK Ideograph, First> and <CJK Ideograph, Last>.
+type dbRange struct {
+	lo, hi rune
+	label  string // The name without the brackets and suffix, such as "CJK Ideograph".
+	entry  Entry  // The entry for lo.
+}
+
+var dbRanges []dbRange
+
+func load() {
+	loadOnce.Do(loadEntries)
+}
+
+func loadEntries() {
+	if unicodeLines == nil {
+		unicodeLines = splitLines(gunzip(unicodeDataGz))
+	}
+	if lineCodes == nil {
+		lineCodes = make([]rune, len(unicodeLines))
+		for i, line := range unicodeLines {
+			lineCodes[i], _ = runeOfLine(i, line)
+		}
+	}
+	for i := 0; i < len(unicodeLines); i++ {
+		if data := lineData(i); isRangeFirst(data) && i+1 < len(unicodeLines) {
+			label, _ := rangeLabel(data)
+			dbRanges = append(dbRanges, dbRange{lineCodes[i], lineCodes[i+1], label, parseEntry(lineCodes[i], data)})
+			i++
+		}
+	}
+}
+
+// lineData returns line i of unicodeLines without its code point.
+func lineData(i int) string {
+	line := unicodeLines[i]
+	return line[strings.IndexAny(line, "\t;")+1:]
+}
+
+// isRangeFirst reports whether data, a line without its code point, is the
+// first line of a range. It is a quicker test than rangeLabel.
+func isRangeFirst(data string) bool {
+	return len(data) > 0 && data[0] == '<' && strings.Contains(data, ", First>")
+}
+
+// isRangeEnd reports whether data, a line without its code point, is the
+// first or last line of a range.
+func isRangeEnd(data string) bool {
+	return isRangeFirst(data) || len(data) > 0 && data[0] == '<' && strings.Contains(data, ", Last>")
+}
+
+// entryOfLine parses the entry on line i of unicodeLines.
+func entryOfLine(i int) Entry {
+	return parseEntry(lineCodes[i], lineData(i))
+}
+
+// LoadUnicodeData replaces the embedded UnicodeData.txt with data, such as a
+// newer or draft version of the file. It must be called before the other
+// functions of the package, and returns an error if data is malformed.
+func LoadUnicodeData(data string) (err error) {
+	loaded := false
+	loadOnce.Do(func() {
+		loaded = true
+		defer func() {
+			if e := recover(); e != nil {
+				err = fmt.Errorf("%v", e)
+				unicodeLines, lineCodes, dbRanges = []string{}, []rune{}, nil
+			}
+		}()
+		unicodeLines = splitLines(strings.ReplaceAll(data, "\r\n", "\n"))
+		loadEntries()
+		// Check every line now, as the entries are otherwise parsed lazily.
+		for i := range unicodeLines {
+			entryOfLine(i)
+		}
+	})
+	if !loaded {
+		return errors.New("ucd: database already loaded")
+	}
+	return err
+}
+
+// Parse calls fn, in order, for the entry of every rune described by data,
+// which is in the format of UnicodeData.txt. Unlike LoadUnicodeData, it
+// leaves the database unchanged, so versions of the file may be compared.
+// It returns an error if data is malformed.
+func Parse(data string, fn func(e Entry)) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%v", e)
+		}
+	}()
+	lines := splitLines(strings.ReplaceAll(data, "\r\n", "\n"))
+	for i := 0; i < len(lines); i++ {
+		r, tab := runeOfLine(i, lines[i])
+		data := lines[i][tab+1:]
+		if label, ok := rangeLabel(data); ok && i+1 < len(lines) {
+			i++
+			hi, _ := runeOfLine(i, lines[i])
+			rng := dbRange{r, hi, label, parseEntry(r, data)}
+			for ; r <= hi; r++ {
+				fn(rangeEntry(rng, r))
+			}
+			continue
+		}
+		fn(parseEntry(r, data))
+	}
+	return nil
+}
+
+// rangeLabel reports whether data is the first line of a range, with a name
+// such as <CJK Ideograph, First>, and if so returns its label.
+func rangeLabel(data string) (string, bool) {
+	name := data
+	if semi := strings.IndexByte(data, ';'); semi >= 0 {
+		name = data[:semi]
+	}
+	const suffix = ", First>"
+	if !strings.HasPrefix(name, "<") || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	return name[1 : len(name)-len(suffix)], true
+}
+
+// rangeEntry returns the entry for r, which is in rng.
+func rangeEntry(rng dbRange, r rune) Entry {
+	e := rng.entry
+	e.Code = r
+	e.Name = rangeName(rng.label, r)
+	return e
+}
+
+// rangeName returns the name of r, which is in the range with the given label.
+// For ranges whose characters have no names, such as private use, it returns
+// a code point label in the style of UAX #44, such as <private-use-E000>.
+func rangeName(label string, r rune) string {
+	switch {
+	case strings.HasPrefix(label, "CJK Ideograph"):
+		return fmt.Sprintf("CJK UNIFIED IDEOGRAPH-%04X", r)
+	case strings.HasPrefix(label, "Tangut Ideograph"):
+		return fmt.Sprintf("TANGUT IDEOGRAPH-%04X", r)
+	case label == "Hangul Syllable":
+		return hangulName(r)
+	case strings.Contains(label, "Surrogate"):
+		return fmt.Sprintf("<surrogate-%04X>", r)
+	case strings.Contains(label, "Private Use"):
+		return fmt.Sprintf("<private-use-%04X>", r)
+	}
+	return fmt.Sprintf("<%s-%04X>", strings.ToLower(label), r)
+}
+
+// Lookup returns the entry for r. If r is unassigned, only the Code of the
+// entry is set.
+func Lookup(r rune) Entry {
+	load()
+	i := sort.Search(len(lineCodes), func(i int) bool { return lineCodes[i] >= r })
+	if i < len(lineCodes) && lineCodes[i] == r && !isRangeEnd(lineData(i)) {
+		return entryOfLine(i)
+	}
+	for _, rng := range dbRanges {
+		if rng.lo <= r && r <= rng.hi {
+			return rangeEntry(rng, r)
+		}
+	}
+	return Entry{Code: r}
+}
+
+// Each calls fn for the entry of every rune in the database, in order.
+func Each(fn func(e Entry)) {
+	each(fn, false)
+}
+
+// each is Each, but if named is set it skips the ranges whose characters
+// have no names, such as private use.
+func each(fn func(e Entry), named bool) {
+	load()
+	for i := 0; i < len(unicodeLines); i++ {
+		r := lineCodes[i]
+		if !isRangeFirst(lineData(i)) {
+			fn(entryOfLine(i))
+			continue
+		}
+		for _, rng := range dbRanges {
+			if rng.lo == r {
+				if named && rangeName(rng.label, r)[0] == '<' {
+					r = rng.hi + 1
+				}
+				for ; r <= rng.hi; r++ {
+					fn(rangeEntry(rng, r))
+				}
+				i++ // Skip the Last line.
+				break
+			}
+		}
+	}
+}
+
+// A nameText is the text of a rune that Search matches, in lower case.
+type nameText struct {
+	code rune
+	text string
+}
+
+// nameIndex holds the search text of every rune that has one, in order.
+// It is built on demand by Search.
+var (
+	nameIndex []nameText
+	nameOnce  sync.Once
+)
+
+func loadNames() {
+	each(func(e Entry) {
+		if text := e.SearchText(); text != "" {
+			nameIndex = append(nameIndex, nameText{e.Code, strings.ToLower(text)})
+		}
+	}, true)
+}
+
+// Search returns the entries whose names match re, as reported by Match.
+// The names are indexed on first use, so later searches are faster, and
+// matched in parallel, in a shard of the index for each processor.
+func Search(re *regexp.Regexp) []Entry {
+	nameOnce.Do(loadNames)
+	shards := runtime.GOMAXPROCS(0)
+	size := (len(nameIndex) + shards - 1) / shards
+	found := make([][]rune, shards)
+	var wg sync.WaitGroup
+	for i := range found {
+		lo, hi := i*size, (i+1)*size
+		if hi > len(nameIndex) {
+			hi = len(nameIndex)
+		}
+		if lo >= hi {
+			break
+		}
+		wg.Add(1)
+		go func(i int, shard []nameText) {
+			defer wg.Done()
+			for _, n := range shard {
+				if re.MatchString(n.text) {
+					found[i] = append(found[i], n.code)
+				}
+			}
+		}(i, nameIndex[lo:hi])
+	}
+	wg.Wait()
+	var list []Entry
+	for _, codes := range found {
+		for _, r := range codes {
+			list = append(list, Lookup(r))
+		}
+	}
+	return list
+}
+
+// Match reports whether the names of e, as given by SearchText but in
+// lower case, match re.
+func (e Entry) Match(re *regexp.Regexp) bool {
+	text := e.SearchText()
+	return text != "" && re.MatchString(strings.ToLower(text))
+}
+
+// SearchText returns the text of e matched by searches: the code point in
+// hex, a tab, and the name, followed by the Unicode 1.0 name and the formal
+// aliases, each after a semicolon and space; for example
+//
+//	000A	<control>; LINE FEED (LF); LINE FEED; NEW LINE; END OF LINE; LF; NL; EOL
+//
+// It returns "" for entries with no name, such as private use characters,
+// which never match.
+func (e Entry) SearchText() string {
+	if !e.Assigned() || e.Name[0] == '<' && e.OldName == "" && Aliases(e.Code) == nil {
+		return ""
+	}
+	line := fmt.Sprintf("%.4X\t%s", e.Code, e.Name)
+	if e.OldName != "" {
+		line += "; " + e.OldName
+	}
+	for _, a := range Aliases(e.Code) {
+		line += "; " + a.Name
+	}
+	return line
+}
@@ -0,0 +1,176 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// The embedded files of the Unicode Character Database, all of the version
+// ucd.Version, are fetched and compressed by gen.go.
+
+//go:generate go run gen.go
+
+// Some files of the Unicode Character Database are too large to embed in
+// the binary. They are read, if present, from the directory named by
+// $UNICODE_UCD, by default the unicode directory in the user's cache.
+// The files of the version of Unicode selected by -ucd are kept in a
+// subdirectory named for the version, such as 12.1.0.
+
+func ucdDir() string {
+	dir := os.Getenv("UNICODE_UCD")
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(cache, "unicode")
+	}
+	if ucdVersion != "" {
+		dir = filepath.Join(dir, ucdVersion)
+	}
+	return dir
+}
+
+// readUCD returns the contents of the named database file from ucdDir,
+// or "" if it is not available.
+func readUCD(name string) string {
+	dir := ucdDir()
+	if dir == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// ucdText returns the named database file from ucdDir, where -update
+// stores the current release, or if it is not there the embedded copy,
+// which is compressed with gzip.
+func ucdText(name string, embedded []byte) string {
+	if text := readUCD(name); text != "" {
+		return text
+	}
+	return gunzip(embedded)
+}
+
+// gunzip returns the decompressed contents of data, which must be valid.
+func gunzip(data []byte) string {
+	z, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		panic("unicode: " + err.Error())
+	}
+	text, err := io.ReadAll(z)
+	if err != nil {
+		panic("unicode: " + err.Error())
+	}
+	return string(text)
+}
+
+// dataSource returns the UnicodeData.txt, or the XML database, named by
+// -data or $UNICODE_DATA, or else the UnicodeData.txt in ucdDir, if any,
+// or "" for the embedded copy.
+func dataSource() string {
+	path := *doData
+	if path == "" {
+		path = os.Getenv("UNICODE_DATA")
+	}
+	if path == "" {
+		path = filepath.Join(ucdDir(), "UnicodeData.txt")
+		if _, err := os.Stat(path); err != nil {
+			path = ""
+		}
+	}
+	return path
+}
+
+// loadData loads the database from dataSource, by way of the compiled
+// database written by -compile if it is up to date, and NameAliases.txt
+// from ucdDir, if it is there, in place of the embedded copies.
+func loadData() {
+	path := dataSource()
+	if !loadCompiled(path) && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		text := string(data)
+		if isUCDXML(path) {
+			text = parseUCDXML(path, text)
+		}
+		if err := ucd.LoadUnicodeData(text); err != nil {
+			fatalf("%s: %s", path, err)
+		}
+	}
+	if text := readUCD("NameAliases.txt"); text != "" {
+		if err := ucd.LoadNameAliases(text); err != nil {
+			fatalf("%s: %s", filepath.Join(ucdDir(), "NameAliases.txt"), err)
+		}
+	}
+}
+
+// compiledFile returns the name of the compiled database in ucdDir.
+func compiledFile() string {
+	return filepath.Join(ucdDir(), "UnicodeData.compiled")
+}
+
+// compiledTag identifies the database from path, or if path is "" the
+// embedded one, by the name, size and time of modification of its file,
+// which for the embedded one is the executable. It returns "" if the file
+// cannot be found.
+func compiledTag(path string) string {
+	if path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return ""
+		}
+		path = exe
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %d %d", path, info.Size(), info.ModTime().UnixNano())
+}
+
+// loadCompiled loads the compiled database, and reports whether it is
+// there and was compiled from path.
+func loadCompiled(path string) bool {
+	tag := compiledTag(path)
+	if tag == "" || ucdDir() == "" {
+		return false
+	}
+	data, err := mapFile(compiledFile())
+	if err != nil {
+		return false
+	}
+	return ucd.LoadCompiled(data, tag) == nil
+}
+
+// compile writes the compiled database, which later runs load in place
+// of parsing the current one while it remains unchanged.
+func compile() {
+	tag := compiledTag(dataSource())
+	if tag == "" || ucdDir() == "" {
+		fatalf("-compile: cannot identify the database or the cache directory")
+	}
+	var b bytes.Buffer
+	if err := ucd.Compile(&b, tag); err != nil {
+		fatalf("-compile: %s", err)
+	}
+	if err := writeFile(compiledFile(), b.Bytes()); err != nil {
+		fatalf("-compile: %s", err)
+	}
+	fmt.Printf("%s: %d bytes\n", compiledFile(), b.Len())
+}
@@ -0,0 +1,351 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The Unicode Character Database is also published in XML, as described
+// by UAX #42, with every property of each code point as an attribute of its
+// element. The flat form of the whole database, ucd.all.flat.xml, is fetched
+// into ucdDir by -update and supplies properties that the text files read
+// here do not, such as Joining_Type and Vertical_Orientation. Named by -data
+// or $UNICODE_DATA, it also takes the place of UnicodeData.txt.
+
+// ucdXMLFile is the name of the XML database in ucdDir.
+const ucdXMLFile = "ucd.all.flat.xml"
+
+// xmlEnumProps lists the enumerated properties taken from the XML database,
+// by their short names, which are its attributes, and their long names.
+var xmlEnumProps = []struct{ short, long string }{
+	{"dt", "Decomposition_Type"},
+	{"nt", "Numeric_Type"},
+	{"hst", "Hangul_Syllable_Type"},
+	{"jt", "Joining_Type"},
+	{"jg", "Joining_Group"},
+	{"InSC", "Indic_Syllabic_Category"},
+	{"InPC", "Indic_Positional_Category"},
+	{"InCB", "Indic_Conjunct_Break"},
+	{"vo", "Vertical_Orientation"},
+	{"GCB", "Grapheme_Cluster_Break"},
+	{"bpt", "Bidi_Paired_Bracket_Type"},
+}
+
+// xmlDataAttrs are the attributes that make up a line of UnicodeData.txt.
+var xmlDataAttrs = []string{"na", "na1", "gc", "ccc", "bc", "dt", "dm", "nt", "nv", "Bidi_M", "suc", "slc", "stc", "blk"}
+
+// xmlProps holds the values of the properties of xmlEnumProps, by short
+// name. It is loaded on demand by xmlProp.
+var xmlProps map[string][]rangeValue
+
+// decompTags maps the values of Decomposition_Type in the XML database to
+// the tags of UnicodeData.txt.
+var decompTags = map[string]string{
+	"com":  "compat",
+	"font": "font",
+	"nb":   "noBreak",
+	"init": "initial",
+	"med":  "medial",
+	"fin":  "final",
+	"iso":  "isolated",
+	"circ": "circle",
+	"sup":  "super",
+	"sub":  "sub",
+	"vert": "vertical",
+	"wide": "wide",
+	"nar":  "narrow",
+	"sml":  "small",
+	"sqr":  "square",
+	"fra":  "fraction",
+}
+
+// isUCDXML reports whether the database file at path is in XML.
+func isUCDXML(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".xml")
+}
+
+// ucdXMLSource returns the XML database: the one named by -data or
+// $UNICODE_DATA, or else the one in ucdDir, or "" if there is none.
+func ucdXMLSource() string {
+	if path := dataSource(); isUCDXML(path) {
+		return path
+	}
+	path := filepath.Join(ucdDir(), ucdXMLFile)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// xmlPropName returns the short name of the property of xmlEnumProps named
+// by name, in either form, or "" if it is not one of them.
+func xmlPropName(name string) string {
+	for _, p := range xmlEnumProps {
+		if looseName(name) == looseName(p.short) || looseName(name) == looseName(p.long) {
+			return p.short
+		}
+	}
+	return ""
+}
+
+// xmlProp returns the value of the property of r with the short name, or ""
+// if it has none, from the XML database, which it loads on first use.
+func xmlProp(name string, r rune) string {
+	if xmlProps == nil {
+		path := ucdXMLSource()
+		if path == "" {
+			fatalf("no XML database: %s not found in %s; get it with -update or name it with -data", ucdXMLFile, ucdDir())
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		parseUCDXML(path, string(data))
+	}
+	if f := lookupRange(xmlProps[name], r); f != nil {
+		return f[0]
+	}
+	return ""
+}
+
+// xmlValue returns the short form of value, a value of the property of
+// xmlEnumProps with the short name, given in any form listed by
+// PropertyValueAliases.txt, such as Dual_Joining for D.
+func xmlValue(name, value string) string {
+	for _, line := range splitLines(ucdText("PropertyValueAliases.txt", propertyValueAliasesGz)) {
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 3 || strings.TrimSpace(fields[0]) != name {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if looseName(strings.TrimSpace(f)) == looseName(value) {
+				return strings.TrimSpace(fields[1])
+			}
+		}
+	}
+	return value
+}
+
+// parseUCDXML parses the XML database, whose file is path, loading the
+// properties of xmlEnumProps, and returns its character data in the form
+// of UnicodeData.txt.
+func parseUCDXML(path, text string) string {
+	keep := make(map[string]bool)
+	for _, a := range xmlDataAttrs {
+		keep[a] = true
+	}
+	xmlProps = make(map[string][]rangeValue)
+	for _, p := range xmlEnumProps {
+		keep[p.short] = true
+		xmlProps[p.short] = nil
+	}
+	var b strings.Builder
+	// A run of characters that UnicodeData.txt records as a range, by
+	// the label of its First and Last lines, such as CJK Ideograph.
+	var run struct {
+		label  string
+		lo, hi rune
+		fields string
+	}
+	n := 0
+	flush := func() {
+		if run.label != "" {
+			fmt.Fprintf(&b, "%04X;<%s, First>;%s\n%04X;<%s, Last>;%s\n", run.lo, run.label, run.fields, run.hi, run.label, run.fields)
+		}
+		run.label = ""
+	}
+	scanUCDXML(path, text, keep, func(kind string, lo, hi rune, attrs map[string]string) {
+		n++
+		for _, p := range xmlEnumProps {
+			v := attrs[p.short]
+			if v == "" {
+				continue
+			}
+			list := xmlProps[p.short]
+			if k := len(list); k > 0 && list[k-1].hi+1 == lo && list[k-1].fields[0] == v {
+				list[k-1].hi = hi
+				continue
+			}
+			xmlProps[p.short] = append(list, rangeValue{lo, hi, []string{v}})
+		}
+		if kind != "char" && kind != "surrogate" || attrs["gc"] == "Cn" {
+			return
+		}
+		fields := xmlDataFields(lo, attrs)
+		label := xmlRangeLabel(lo, attrs)
+		if label != "" {
+			if run.label == label && run.fields == fields && run.hi+1 == lo {
+				run.hi = hi
+				return
+			}
+			flush()
+			run.label, run.lo, run.hi, run.fields = label, lo, hi, fields
+			return
+		}
+		flush()
+		for r := lo; r <= hi; r++ {
+			name := attrs["na"]
+			switch {
+			case strings.Contains(name, "#"):
+				name = strings.ReplaceAll(name, "#", fmt.Sprintf("%04X", r))
+			case name == "" && attrs["gc"] == "Cc":
+				name = "<control>"
+			case name == "":
+				fatalf("%s: %04X has no name", path, r)
+			}
+			fmt.Fprintf(&b, "%04X;%s;%s\n", r, name, fields)
+		}
+	})
+	flush()
+	if n == 0 {
+		fatalf("%s: no code points; not the Unicode Character Database in XML", path)
+	}
+	return b.String()
+}
+
+// scanUCDXML calls fn for each element of the repertoire of the XML
+// database, a char, reserved, noncharacter or surrogate, with the range of
+// code points it describes and those of its attributes named in keep,
+// including the ones it takes from its group. As in the published files,
+// each element must be on a line of its own.
+func scanUCDXML(path, text string, keep map[string]bool, fn func(kind string, lo, hi rune, attrs map[string]string)) {
+	var group map[string]string
+	repertoire := false
+	for i, line := range splitLines(text) {
+		line = strings.TrimSpace(line)
+		if len(line) < 2 || line[0] != '<' {
+			continue
+		}
+		kind := line[1:]
+		if j := strings.IndexAny(kind[1:], " />"); j >= 0 {
+			kind = kind[:j+1]
+		}
+		switch kind {
+		case "repertoire":
+			repertoire = true
+			continue
+		case "/repertoire":
+			repertoire = false
+			continue
+		case "group":
+			group = xmlAttrs(line, keep)
+			continue
+		case "/group":
+			group = nil
+			continue
+		case "char", "reserved", "noncharacter", "surrogate":
+		default:
+			continue
+		}
+		if !repertoire {
+			continue
+		}
+		attrs := xmlAttrs(line, keep)
+		for k, v := range group {
+			if _, ok := attrs[k]; !ok {
+				attrs[k] = v
+			}
+		}
+		var lo, hi rune
+		switch {
+		case attrs["cp"] != "":
+			lo = parseRune(attrs["cp"])
+			hi = lo
+		case attrs["first-cp"] != "" && attrs["last-cp"] != "":
+			lo, hi = parseRune(attrs["first-cp"]), parseRune(attrs["last-cp"])
+		default:
+			fatalf("%s: line %d: no code point", path, i+1)
+		}
+		fn(kind, lo, hi, attrs)
+	}
+}
+
+// xmlAttrs returns the attributes of the element on the line that are
+// named in keep, and its code points.
+func xmlAttrs(line string, keep map[string]bool) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		eq := strings.Index(line, `="`)
+		if eq < 0 {
+			return attrs
+		}
+		name := line[strings.LastIndexAny(line[:eq], " \t")+1 : eq]
+		line = line[eq+2:]
+		end := strings.IndexByte(line, '"')
+		if end < 0 {
+			return attrs
+		}
+		if keep[name] || name == "cp" || name == "first-cp" || name == "last-cp" {
+			attrs[name] = html.UnescapeString(line[:end])
+		}
+		line = line[end+1:]
+	}
+}
+
+// xmlRangeLabel returns the label under which UnicodeData.txt records the
+// character at r, with the attributes, as part of a range, or "" if it
+// lists it on its own.
+func xmlRangeLabel(r rune, attrs map[string]string) string {
+	switch {
+	case attrs["gc"] == "Co" && attrs["blk"] == "Sup_PUA_A":
+		return "Plane 15 Private Use"
+	case attrs["gc"] == "Co" && attrs["blk"] == "Sup_PUA_B":
+		return "Plane 16 Private Use"
+	case attrs["gc"] == "Co":
+		return "Private Use"
+	case attrs["gc"] == "Cs" && attrs["blk"] == "High_Surrogates":
+		return "Non Private Use High Surrogate"
+	case attrs["gc"] == "Cs" && attrs["blk"] == "High_PU_Surrogates":
+		return "Private Use High Surrogate"
+	case attrs["gc"] == "Cs":
+		return "Low Surrogate"
+	case hangulSBase <= r && r < hangulSBase+hangulSCount:
+		return "Hangul Syllable"
+	case strings.HasPrefix(attrs["na"], "CJK UNIFIED IDEOGRAPH-"):
+		return "CJK Ideograph"
+	case strings.HasPrefix(attrs["na"], "TANGUT IDEOGRAPH-"):
+		return "Tangut Ideograph"
+	}
+	return ""
+}
+
+// xmlDataFields returns the fields of the line of UnicodeData.txt for the
+// character at r with the attributes, after its name.
+func xmlDataFields(r rune, attrs map[string]string) string {
+	decomp := ""
+	if dm := attrs["dm"]; dm != "#" && attrs["dt"] != "none" && (r < hangulSBase || hangulSBase+hangulSCount <= r) {
+		decomp = dm
+		if dt := attrs["dt"]; dt != "can" {
+			decomp = "<" + decompTags[dt] + "> " + dm
+		}
+	}
+	var decimal, digit, numeric string
+	switch attrs["nt"] {
+	case "De":
+		decimal, digit, numeric = attrs["nv"], attrs["nv"], attrs["nv"]
+	case "Di":
+		digit, numeric = attrs["nv"], attrs["nv"]
+	case "Nu":
+		numeric = attrs["nv"]
+	}
+	mapping := func(name string) string {
+		if m := attrs[name]; m != "#" {
+			return m
+		}
+		return ""
+	}
+	return strings.Join([]string{
+		attrs["gc"], attrs["ccc"], attrs["bc"], decomp, decimal, digit, numeric,
+		attrs["Bidi_M"], attrs["na1"], "", mapping("suc"), mapping("slc"), mapping("stc"),
+	}, ";")
+}
@@ -10,88 +10,649 @@ usage: unicode [-c] [-d] [-n] [-t]
 	-c: args are hex; output characters (xyz)
 	-n: args are characters; output hex (23 or 23-44)
 	-g: args are regular expressions for matching names
+	-v re: exclude the characters whose names match re; with -g, or alone or with other filters to list the rest
+	-case: match -g and -v against the names as in the database, in upper case with upper-case hex ('\bCJK\b', '^1F6')
+	-field f: match -g and -v against field f of UnicodeData.txt instead of the names (code, name, category, ccc, bidi,
+	      decomposition, decimal, digit, numeric, mirrored, oldname, comment, upper, lower, title, or 0 to 14)
+	-f: args are words of names, in any order and allowing typos; list the best matches
+	-N: args are exact names or aliases of characters, ignoring case ('EM DASH')
+	-batch file: read queries from file (- for standard input), one to a line: hex codes or ranges (-c), or g:regexp (-g); -d by default
 	-d: output textual description
 	-t: output plain text, not one char per line
 	-U: output full Unicode description
+	-lang code: add the CLDR name and keywords in the language (de, pt_BR, ...) to -d and -U, especially of emoji
+	-cat: list characters in, or restrict output to, the comma-separated
+	      general categories (Sm, L, ...)
+	-p: likewise for the comma-separated binary properties (White_Space, Dash, ...)
+	-age: likewise for the Unicode version of introduction (15.0, '>=15.0', ...)
+	-value: likewise for the numeric values, as numbers, ranges or comparisons (1/2, 1-9, '>=1000', ...)
+	-script: likewise for the scripts, by the Script property alone (Arab, Greek, ...)
+	-scriptx: likewise for the scripts, by Script_Extensions (Deva, Greek, ...)
+	-range, -block: likewise for comma-separated code point ranges (2190-2BFF) or blocks (Cyrillic, 'Latin Extended-A')
+	-q: likewise for a query over properties, with | & ! and parentheses ('Script=Greek & gc=Lu & !Age>15.0', 'Dash | lb=HY')
+	-set: likewise for an ICU UnicodeSet pattern ('[[:Latin:]&[:Ll:]-[a-m]]', '[\p{Greek}&\P{Ll}]')
+	-rand n: choose n of the characters found or listed at random (never surrogates); -seed s makes the choice repeatable
+	-sortby key: order the characters found or listed by code (the default), name, category, block or age
+	-count: print only the number of characters (and sequences) found or listed
+	-byblock: group the characters found or listed under headings naming their blocks
+	-cols: print the characters in a grid as wide as the terminal, each above its code point
+	-ranges: print the characters found or listed as runs of code points (0410-044f), in the form of -c args
+	-gotable name: print the characters found or listed as Go source declaring the *unicode.RangeTable name
+	-class dialect: print them as a regexp character class for dialect go, pcre, js or python ([\x{2190}-\x{21FF}])
+	-near n: show the n code points before and after each character, described (-d) unless another format is given
+	-max n, -skip n: page through what is found or listed, skipping the first n, printing at most n and noting how many remain
+	-han: args are regular expressions for matching the meanings and readings
+	      of Han characters (horse, ma3, uma)
+	-emoji: args are regular expressions for matching the short names and
+	      keywords of emoji (face with tears)
+	-explain: args are emoji or other sequences; explain their code points
+	-emoji-list: list the emoji in CLDR order, as in pickers, by group and subgroup, with their qualification; args, if any, are
+	      regular expressions selecting groups or subgroups (flags, 'face-')
+	-flag: args are region codes (NL, GB-SCT) or flags; convert one to the other
+	-tone n: apply skin tone n, 1 (light) to 5 (dark), to the emoji args; -tone strip or -tone show to remove or report tones
+	-vs: args are characters; print their variation sequences (text or emoji style, CJK compatibility forms)
+	-rel: args are characters; show the related characters: case pairs, decompositions, cross references, confusables
+	-render file: args are characters; draw them with the TrueType font -font f, at -fontsize n pixels (64), as a contact sheet
+	      in file, a PNG or SVG image by its suffix; a % verb in the name, as in U+%04X.png, makes one image per character
+	-nfc, -nfd, -nfkc, -nfkd: args (or standard input) are text; show the normalized forms
+	-decomp: print the recursive canonical and compatibility decomposition of each character
+	-props: print every property of each character, enumerated and binary, in the \p{...} notation of -set (\p{gc=Zs}, \p{White_Space})
+	-fonts: list the installed fonts, found by fontconfig or in the system font directories, that have a glyph for each character
+	-variants: args are characters; list the characters whose decompositions contain them (e: é, ℯ, ᵉ, ...)
+	-fold: args (or standard input) are text; show their full case folding
+	-upper, -lower, -title: args (or standard input) are text; convert their case and show the mappings applied
+	-confuse: args are characters or strings; show their UTS #39 skeletons and the characters confusable with each (needs confusables.txt)
+	-spoof: args are strings; report their scripts, UTS #39 restriction level and the characters that mix scripts
+	-ident: args are strings; check them as UAX #31 and Go identifiers and name the first invalid character
+	-idna: args are domain names; convert them to A-labels (xn--) and U-labels by UTS #46 and name what is disallowed (needs IdnaMappingTable.txt)
+	-graphemes: args (or standard input) are text; split them into extended grapheme clusters
+	-words, -sentences: args (or standard input) are text; split them into words or sentences, showing the boundary offsets and the break property of each rune
+	-linebreak: args (or standard input) are text; mark their UAX #14 line break opportunities
+	-width: args (or standard input) are text; compute their display width in a terminal
+	-probe: likewise, or without args a set of hard cases; print each to the terminal, ask it where the cursor went, and report where its width differs from -width
+	-bidi: args (or standard input) are text; show the UAX #9 bidi class and embedding level of each rune and the display order
+	-compose: args are a base character and combining marks; show their combining classes and NFC precomposed form
+	-compose-pair: args are two code points (0065 0301) or characters; show the character they compose to canonically, if any, or why not
+	-minversion: args are strings (or lines of standard input); show the oldest Unicode version with all their characters and the newest characters, which set it
+	-sortkey: args (or standard input) are text; show their UCA collation elements and sort key
+	-sort: args (or the lines of standard input) are text; sort them by UCA, or with -locale tag by CLDR's tailoring for that locale
+	-ascii: args (or standard input) are text; convert them to ASCII by decomposing, dropping marks and transliterating
+	-style name: args (or standard input) are text; write their ASCII in the style (bold, italic, fraktur, double-struck, monospace,
+	      fullwidth, circled, small-caps, ...), or with -style plain turn styled text back into ASCII
+	-serve addr: serve lookups (/char/1F600) and name searches (/search?q=greek.*alpha) over HTTP as JSON or HTML
+	-torture: print test strings for hard cases of text handling (long clusters, mark pileups, bidi controls, noncharacters, invalid UTF-8, joiners, plane edges); -t for raw text
+	-blocks, -planes: list the blocks or planes with their ranges and numbers of assigned characters; both together list the blocks of each plane
+	-chart block: print an HTML code chart of the block, a grid of glyphs and code points followed by the names (-chart Arrows -o arrows.html)
+	-report: args are files (or standard input); summarize their text: sizes, counts by script, category and block, controls and bidi controls
+	-suspect: likewise; show where invisible or suspicious characters (zero width, bidi controls, NBSP, soft hyphens, ...) and invalid UTF-8 are
+	-newlines: likewise; count the line terminators (LF, CRLF, CR, NEL, VT, FF, LS, PS), flag mixed conventions and show where the unusual ones are
+	-scan: args are source files or directories (.); report bidi controls, invisible characters and identifiers that mix scripts, look like ASCII or are not NFC; exit 1 if any
+	-strict: exit with status 3 if a character looked up is unassigned, 4 if -g finds nothing, 5 if input is not valid UTF-8
+	-list block, -list script:name: describe every assigned character of a block ('Box Drawing') or script (script:Runic)
+	-diff: args are two Unicode versions (15.0 16.0); print the characters added, removed, renamed or changed between them, restricted by -block, -range or -cat
+	-enc list: print the bytes of each character in the comma-separated legacy encodings (latin1,shiftjis,gb18030), or note it has none
+	-o file, -oenc encoding: write the output to a file, or in an encoding such as utf-16le-bom, utf-8-bom or shiftjis
+	-copy: also copy the characters found to the clipboard; -paste: take the text on the clipboard as an argument (pbcopy, clip, wl-copy, xclip or xsel)
 
 Default behavior sniffs the arguments to select -c vs. -n.
+
+Flags must come before the arguments: unicode -cat So,Sm -g arrow, not
+unicode -g arrow -cat So,Sm, which takes -cat and So,Sm as patterns.
+
+Hex arguments may be ranges such as 2190-21FF, open at either end
+(2600- runs to the last assigned character, -7F starts at 0; put --
+before an argument that begins with a dash), and comma-separated
+lists such as 0-1F,7F-9F. The same forms are accepted by -range.
+
+Text read from standard input may be in UTF-8, UTF-16 or UTF-32, with or
+without a byte order mark, or failing those in Latin-1. Anything but
+plain UTF-8 is converted, and its encoding reported on standard error.
+
+-d and -U show how to type each character beyond ASCII, where known:
+its sequence for the X11 Compose key, its Vim digraph, and its LaTeX.
+
+Files of the Unicode Character Database too large to embed, such as
+NamesList.txt, are read if present from the directory $UNICODE_UCD,
+by default unicode in the user's cache directory. NamesList.txt adds
+the code chart annotations to -U output, and Unihan_Readings.txt adds
+the meanings and readings of Han characters to -d and -U output.
+The CLDR emoji annotations, annotations/en.xml, add keywords to -emoji.
+Those of other languages, for -lang, are downloaded there on first use.
+confusables.txt, from the security data of UTS #39, is needed by -confuse.
+IdnaMappingTable.txt, of UTS #46, is needed by -idna and adds the IDNA status to -U.
+ucd.all.flat.xml, the database in XML of UAX #42, adds properties such as
+Joining_Type, Indic_Syllabic_Category and Vertical_Orientation to -props,
+-q and -set.
+
+-update downloads the current release of these files and of the embedded
+ones into the same directory, where they take the place of the embedded
+copies. The embedded UnicodeData.txt may also be replaced by another,
+such as a draft version, or by ucd.all.flat.xml, named by -data or
+$UNICODE_DATA.
+-compile writes a compiled form of the database to the same directory,
+which later runs load without parsing until the database changes.
+-ucd selects an earlier version of Unicode, such as 12.1, whose files
+are downloaded on first use into a subdirectory named for the version.
+Properties taken from Go's unicode package, such as the scripts of
+-script and the binary properties of -p, remain those of Go's tables.
+
+The character data is also available to Go programs as package
+robpike.io/cmd/unicode/ucd.
 */
 package main // import "robpike.io/cmd/unicode"
 
 import (
+	"bufio"
 	"bytes"
 	_ "embed"
 	"flag"
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"robpike.io/cmd/unicode/ucd"
 )
 
 var (
-	doNum  = flag.Bool("n", false, "output numeric values")
-	doChar = flag.Bool("c", false, "output characters")
-	doText = flag.Bool("t", false, "output plain text")
-	doDesc = flag.Bool("d", false, "describe the characters from the Unicode database, in simple form")
-	doUnic = flag.Bool("u", false, "describe the characters from the Unicode database, in Unicode form")
-	doUNIC = flag.Bool("U", false, "describe the characters from the Unicode database, in glorious detail")
-	doGrep = flag.Bool("g", false, "grep for argument string in data")
+	doNum       = flag.Bool("n", false, "output numeric values")
+	doChar      = flag.Bool("c", false, "output characters")
+	doText      = flag.Bool("t", false, "output plain text")
+	doDiff      = flag.Bool("diff", false, "args are two Unicode versions; print the characters added or changed between them")
+	doCopy      = flag.Bool("copy", false, "also copy the characters found to the clipboard")
+	doData      = flag.String("data", "", "read the character database from the UnicodeData.txt or ucd.all.flat.xml `file` instead of the embedded copy")
+	doDesc      = flag.Bool("d", false, "describe the characters from the Unicode database, in simple form")
+	doUnic      = flag.Bool("u", false, "describe the characters from the Unicode database, in Unicode form")
+	doUNIC      = flag.Bool("U", false, "describe the characters from the Unicode database, in glorious detail")
+	doLang      = flag.String("lang", "", "add the CLDR names and keywords in the `language`, such as de, to the descriptions")
+	doGrep      = flag.Bool("g", false, "grep for argument string in data")
+	doCase      = flag.Bool("case", false, "match -g and -v regexps against names in upper case, as in the database")
+	doField     = flag.String("field", "", "match -g and -v regexps against database `field`, such as category, instead of names")
+	doInvert    = flag.String("v", "", "exclude characters whose names match `regexp`, as with -g")
+	doNames     = flag.Bool("N", false, "args are exact character names or aliases, ignoring case")
+	doBatch     = flag.String("batch", "", "read queries, hex codes or ranges or g:regexp, one to a line from `file`, or - for standard input")
+	doFuzzy     = flag.Bool("f", false, "search for the characters whose names best match the argument words, allowing typos")
+	doHan       = flag.Bool("han", false, "grep for argument string in the meanings and readings of Han characters")
+	doEmo       = flag.Bool("emoji", false, "grep for argument string in the short names and keywords of emoji")
+	doEnc       = flag.String("enc", "", "print the bytes of the characters in the comma-separated legacy `encodings`, such as latin1,shiftjis")
+	doExpl      = flag.Bool("explain", false, "explain the code points of each argument, such as an emoji sequence")
+	doEmojiList = flag.Bool("emoji-list", false, "list the emoji in CLDR order by group and subgroup")
+	doFlag      = flag.Bool("flag", false, "convert region codes such as NL to flag emoji and back")
+	doNFC       = flag.Bool("nfc", false, "show the NFC normalization of the arguments")
+	doNFD       = flag.Bool("nfd", false, "show the NFD normalization of the arguments")
+	doNFKC      = flag.Bool("nfkc", false, "show the NFKC normalization of the arguments")
+	doNFKD      = flag.Bool("nfkd", false, "show the NFKD normalization of the arguments")
+	doDecomp    = flag.Bool("decomp", false, "print the full recursive decomposition of each character")
+	doProps     = flag.Bool("props", false, "print every property of each character in \\p{...} notation")
+	doFonts     = flag.Bool("fonts", false, "list the installed fonts that have a glyph for each character")
+	doVariants  = flag.Bool("variants", false, "args are characters; list the characters whose decompositions contain them")
+	doFold      = flag.Bool("fold", false, "show the full case folding of the arguments")
+	doUCD       = flag.String("ucd", "", "use the database of Unicode `version`, such as 12.1, downloading it if need be")
+	doUpdate    = flag.Bool("update", false, "download the current Unicode Character Database into the cache directory")
+	doUpper     = flag.Bool("upper", false, "convert the arguments to upper case")
+	doLower     = flag.Bool("lower", false, "convert the arguments to lower case")
+	doTitle     = flag.Bool("title", false, "convert the arguments to title case")
+	doCompile   = flag.Bool("compile", false, "write the database in compiled form to the cache directory, for faster startup")
+	doConf      = flag.Bool("confuse", false, "list the characters confusable with each character of the arguments")
+	doSpoof     = flag.Bool("spoof", false, "analyze the scripts of the arguments for spoofing")
+	doIdent     = flag.Bool("ident", false, "check whether the arguments are valid identifiers")
+	doIDNA      = flag.Bool("idna", false, "convert the domain names of the arguments to and from A-labels by UTS #46")
+	doGraph     = flag.Bool("graphemes", false, "split the arguments into grapheme clusters")
+	doWords     = flag.Bool("words", false, "split the arguments into words")
+	doSent      = flag.Bool("sentences", false, "split the arguments into sentences")
+	doLine      = flag.Bool("linebreak", false, "mark the line break opportunities in the arguments")
+	doWidth     = flag.Bool("width", false, "compute the display width of the arguments")
+	doProbe     = flag.Bool("probe", false, "measure the width the terminal gives each argument and compare it with the computed width")
+	doBidi      = flag.Bool("bidi", false, "run the bidirectional algorithm over the arguments")
+	doComp      = flag.Bool("compose", false, "show how each argument, a base character and combining marks, composes")
+	doCompPair  = flag.Bool("compose-pair", false, "show the canonical composite of the two code points of the arguments")
+	doMinVer    = flag.Bool("minversion", false, "show the oldest version of Unicode that assigns every character of the arguments, and the characters that require it")
+	doSortK     = flag.Bool("sortkey", false, "show the collation elements and sort key of the arguments")
+	doSort      = flag.Bool("sort", false, "sort the lines of standard input by the Unicode Collation Algorithm")
+	doLocale    = flag.String("locale", "", "sort with the CLDR tailoring for `locale`, such as de or sv")
+	doASCII     = flag.Bool("ascii", false, "convert the arguments to an ASCII approximation")
+	doStyle     = flag.String("style", "", "write the ASCII of the arguments in the `style`, or plain to undo it")
+	doServe     = flag.String("serve", "", "serve queries over HTTP on `address`, such as :8080")
+	doTort      = flag.Bool("torture", false, "print strings that exercise hard cases of text handling")
+	doVS        = flag.Bool("vs", false, "args are characters; print their variation sequences")
+	doRel       = flag.Bool("rel", false, "args are characters; print the characters related to them")
+	doRender    = flag.String("render", "", "args are characters; draw them with -font into the PNG or SVG `file`")
+	doFont      = flag.String("font", "", "the TrueType font `file` for -render")
+	doFontSize  = flag.Int("fontsize", 64, "the size of the em of -font in `pixels`")
+	doTone      = flag.String("tone", "", "apply skin `tone` 1 (light) to 5 (dark) to each emoji argument; strip or show tones")
+	doCat       = flag.String("cat", "", "restrict to characters in the comma-separated general `categories`")
+	doPaste     = flag.Bool("paste", false, "add the text on the clipboard to the args")
+	doProp      = flag.String("p", "", "restrict to characters with any of the comma-separated binary `properties`")
+	doValue     = flag.String("value", "", "restrict to characters whose numeric value is one of the comma-separated `numbers`, such as 1/2 or 1-9")
+	doAge       = flag.String("age", "", "restrict to characters whose Unicode version satisfies `comparison`, such as >=15.0")
+	doRange     = flag.String("range", "", "restrict to characters in the comma-separated code point `ranges`, such as 2190-2BFF")
+	doBlocks    = flag.Bool("blocks", false, "list the blocks, with their ranges and numbers of characters assigned")
+	doPlanes    = flag.Bool("planes", false, "list the planes, with their ranges and numbers of characters assigned")
+	doChart     = flag.String("chart", "", "print an HTML code chart of the `block`, such as Arrows")
+	doReport    = flag.Bool("report", false, "args are files; summarize the characters of their text, or of standard input")
+	doSuspect   = flag.Bool("suspect", false, "args are files; show the invisible and suspicious characters of their text, or of standard input")
+	doNewlines  = flag.Bool("newlines", false, "args are files; count the line terminators of their text, or of standard input, and show where the unusual ones are")
+	doScan      = flag.Bool("scan", false, "args are source files or directories; report Unicode that can hide what code does")
+	doStrict    = flag.Bool("strict", false, "exit with distinct statuses when a character is unassigned, -g finds nothing or input is not valid UTF-8")
+	doBlock     = flag.String("block", "", "restrict to characters in the comma-separated `blocks`, such as Cyrillic")
+	doQuery     = flag.String("q", "", "restrict to characters satisfying the property `query`, such as 'sc=Greek & gc=Lu'")
+	doByBlock   = flag.Bool("byblock", false, "group the characters printed under the names of their blocks")
+	doCols      = flag.Bool("cols", false, "print the characters in a grid as wide as the terminal, with their code points")
+	doRanges    = flag.Bool("ranges", false, "print the characters as ranges of code points, such as 0410-044f")
+	doGoTable   = flag.String("gotable", "", "print the characters as Go source for a *unicode.RangeTable named `name`")
+	doClass     = flag.String("class", "", "print the characters as a regexp character class in `dialect` go, pcre, js or python")
+	doRand      = flag.Int("rand", 0, "choose `n` of the characters at random")
+	doSeed      = flag.Int64("seed", 0, "seed the random choice of -rand with `s`, for a repeatable sample")
+	doSortBy    = flag.String("sortby", "", "order the characters by `key`: code, name, category, block or age")
+	doCount     = flag.Bool("count", false, "print only the number of characters and sequences selected")
+	doOut       = flag.String("o", "", "write the output to `file`")
+	doOenc      = flag.String("oenc", "", "write the output in `encoding`, such as utf-16le-bom or shiftjis")
+	doNear      = flag.Int("near", 0, "show the `n` code points before and after each character, with their names")
+	doList      = flag.String("list", "", "describe every character of the `block`, or of the script given as script:name")
+	doMax       = flag.Int("max", 0, "print at most `n` of the characters and sequences selected")
+	doSkip      = flag.Int("skip", 0, "skip the first `n` characters and sequences selected")
+	doSet       = flag.String("set", "", "restrict to characters in the ICU UnicodeSet `pattern`, such as '[[:Latin:]&[:Ll:]]'")
+	doScript    = flag.String("script", "", "restrict to characters whose Script is one of the comma-separated `scripts`")
+	doScx       = flag.String("scriptx", "", "restrict to characters used with any of the comma-separated `scripts`, such as Deva")
 )
 
 var printRange = false
 
-//go:generate sh -c "curl http://ftp.unicode.org/Public/UNIDATA/UnicodeData.txt >UnicodeData.txt"
-var (
-	//go:embed UnicodeData.txt
-	unicodeDataTxt string
-	unicodeLines   = splitLines(unicodeDataTxt)
-)
+// stdout buffers the output of the listings, which is written as it is
+// produced, so a reader such as head sees it at once. Writers that share
+// standard output must flush it first.
+var stdout = bufio.NewWriter(os.Stdout)
 
 func main() {
 	flag.Usage = usage
 	flag.Parse()
+	if *doOut != "" || *doOenc != "" {
+		redirectOutput(*doOut, *doOenc)
+		defer finishOutput()
+	}
+	// Runs before finishOutput, which it calls before exiting.
+	defer exitStrict()
+	if *doPaste {
+		pasteArgs()
+	}
+	if *doUCD != "" {
+		useVersion(*doUCD)
+	}
+	if *doUpdate {
+		update()
+		return
+	}
+	loadData()
+	switch {
+	case *doExpl:
+		explain(flag.Args())
+		return
+	case *doEmojiList:
+		emojiCatalog(flag.Args())
+		return
+	case *doFlag:
+		flags(flag.Args())
+		return
+	case *doVS:
+		printSequences(argsAreVariations(flag.Args()))
+		return
+	case *doRel:
+		related(flag.Args())
+		return
+	case *doRender != "":
+		render(*doRender, flag.Args())
+		return
+	case *doTone != "":
+		tone(*doTone, flag.Args())
+		return
+	case normalizing():
+		normalize(flag.Args())
+		return
+	case *doFold:
+		fold(flag.Args())
+		return
+	case *doConf:
+		confuse(flag.Args())
+		return
+	case *doSpoof:
+		spoof(flag.Args())
+		return
+	case *doIdent:
+		ident(flag.Args())
+		return
+	case *doIDNA:
+		idna(flag.Args())
+		return
+	case *doGraph:
+		graphemes(flag.Args())
+		return
+	case *doWords:
+		words(flag.Args())
+		return
+	case *doSent:
+		sentences(flag.Args())
+		return
+	case *doLine:
+		lineBreakOpportunities(flag.Args())
+		return
+	case *doWidth:
+		width(flag.Args())
+		return
+	case *doProbe:
+		probe(flag.Args())
+		return
+	case *doBidi:
+		bidi(flag.Args())
+		return
+	case *doComp:
+		compose(flag.Args())
+		return
+	case *doCompPair:
+		composePair(flag.Args())
+		return
+	case *doMinVer:
+		minVersion(flag.Args())
+		return
+	case *doSortK:
+		showSortKeys(flag.Args())
+		return
+	case *doSort:
+		sortLines(flag.Args(), *doLocale)
+		return
+	case *doASCII:
+		ascii(flag.Args())
+		return
+	case *doStyle != "":
+		style(*doStyle, flag.Args())
+		return
+	case *doServe != "":
+		serve(*doServe)
+		return
+	case *doBlocks || *doPlanes:
+		catalog(*doBlocks, *doPlanes)
+		return
+	case *doChart != "":
+		chart(*doChart)
+		return
+	case *doReport:
+		report(flag.Args())
+		return
+	case *doSuspect:
+		suspect(flag.Args())
+		return
+	case *doNewlines:
+		newlines(flag.Args())
+		return
+	case *doScan:
+		scan(flag.Args())
+		return
+	case *doDiff:
+		diff(flag.Args())
+		return
+	case *doCompile:
+		compile()
+		return
+	case *doTort:
+		torture()
+		return
+	case *doUpper:
+		convertCase("upper", flag.Args())
+		return
+	case *doLower:
+		convertCase("lower", flag.Args())
+		return
+	case *doTitle:
+		convertCase("title", flag.Args())
+		return
+	}
+	if *doBatch != "" && !*doNum && !*doChar {
+		describeByDefault()
+	}
 	mode()
 	var codes []rune
 	switch {
+	case *doBatch != "":
+		codes = batch(*doBatch)
+	case len(flag.Args()) == 0:
+		codes = allRunes()
 	case *doGrep:
 		codes = argsAreRegexps()
+	case *doFuzzy:
+		codes = argsAreFuzzy()
+	case *doNames:
+		codes = argsAreNames()
+	case *doHan:
+		codes = argsAreHanRegexps()
+	case *doVariants:
+		codes = argsAreVariants()
+	case *doEmo:
+		// Emoji are sequences, printed below.
 	case *doChar:
 		codes = argsAreNumbers()
 	case *doNum:
 		codes = argsAreChars()
 	}
+	if *doLang != "" {
+		describeByDefault()
+	}
+	if *doNear != 0 {
+		codes = near(codes, *doNear)
+	}
+	if *doRange != "" {
+		codes = inRanges(codes, *doRange, false)
+	}
+	if *doBlock != "" {
+		codes = inRanges(codes, *doBlock, true)
+	}
+	if *doList != "" {
+		codes = listed(codes, *doList)
+	}
+	if *doCat != "" {
+		codes = inCategories(codes, *doCat)
+	}
+	if *doProp != "" {
+		codes = withProps(codes, *doProp)
+	}
+	if *doAge != "" {
+		codes = ofAge(codes, *doAge)
+	}
+	if *doValue != "" {
+		codes = ofValue(codes, *doValue)
+	}
+	if *doScript != "" {
+		codes = inScriptsOnly(codes, *doScript)
+	}
+	if *doScx != "" {
+		codes = inScripts(codes, *doScx)
+	}
+	if *doInvert != "" {
+		codes = notMatching(codes, *doInvert)
+	}
+	if *doQuery != "" {
+		codes = matchingQuery(codes, *doQuery)
+	}
+	if *doSet != "" {
+		codes = inSet(codes, *doSet)
+	}
+	if *doRand > 0 {
+		codes = sample(codes, *doRand, *doSeed)
+	}
+	if *doSortBy != "" {
+		sortBy(codes, *doSortBy)
+	}
+	seqs := sequences()
+	if *doGrep && len(codes)+len(seqs) == 0 {
+		failStrict(exitNotFound, "nothing matches %s", strings.Join(flag.Args(), " "))
+	}
+	if *doCount {
+		fmt.Println(len(codes) + len(seqs))
+		return
+	}
+	codes, seqs, more := paginate(codes, seqs)
+	if *doCopy {
+		text := string(codes)
+		for _, s := range seqs {
+			text += s.text
+		}
+		copyText(text)
+	}
+	output(codes)
+	printSequences(seqs)
+	if more > 0 {
+		fmt.Fprintf(os.Stderr, "... and %d more\n", more)
+	}
+}
+
+// paginate applies -skip and -max to the characters and then the sequences
+// found, and returns how many more there are beyond -max.
+func paginate(codes []rune, seqs []namedSeq) ([]rune, []namedSeq, int) {
+	if *doSkip < 0 || *doMax < 0 {
+		fatalf("-skip and -max must not be negative")
+	}
+	skip := *doSkip
+	if skip > len(codes) {
+		skip -= len(codes)
+		codes = nil
+		if skip > len(seqs) {
+			skip = len(seqs)
+		}
+		seqs = seqs[skip:]
+	} else {
+		codes = codes[skip:]
+	}
+	more := len(codes) + len(seqs) - *doMax
+	if *doMax == 0 || more <= 0 {
+		return codes, seqs, 0
+	}
+	if len(codes) >= *doMax {
+		return codes[:*doMax], nil, more
+	}
+	return codes, seqs[:*doMax-len(codes)], more
+}
+
+// near returns the n code points before and after each of codes, once each,
+// which are described unless another format is selected.
+func near(codes []rune, n int) []rune {
+	if n < 0 {
+		fatalf("-near must not be negative")
+	}
+	describeByDefault()
+	var out []rune
+	seen := make(map[rune]bool)
+	for _, c := range codes {
+		lo, hi := c-rune(n), c+rune(n)
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > unicode.MaxRune {
+			hi = unicode.MaxRune
+		}
+		for r := lo; r <= hi; r++ {
+			if !seen[r] {
+				seen[r] = true
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+// describeByDefault selects -d if no other output format is selected.
+func describeByDefault() {
+	if !*doUnic && !*doUNIC && !*doProps && !*doFonts && !*doCols && !*doText && !*doRanges && *doClass == "" && *doGoTable == "" {
+		*doDesc = true
+	}
+}
+
+// sequences returns the named or emoji sequences selected by the arguments.
+func sequences() []namedSeq {
+	switch {
+	case *doGrep && !filtering() && *doField == "":
+		return grepSequences()
+	case *doEmo:
+		return argsAreEmojiRegexps()
+	case *doNum && !searching() && len(flag.Args()) > 0:
+		return argsAreSequences()
+	}
+	return nil
+}
+
+// output prints codes in the format selected by the flags.
+func output(codes []rune) {
+	if *doByBlock {
+		outputByBlock(codes)
+		return
+	}
+	if *doRanges {
+		outputRanges(codes)
+		return
+	}
+	if *doGoTable != "" {
+		outputGoTable(*doGoTable, codes)
+		return
+	}
+	if *doClass != "" {
+		outputClass(*doClass, codes)
+		return
+	}
+	if *doEnc != "" {
+		outputEncodings(*doEnc, codes)
+		return
+	}
+	if *doDecomp {
+		decompose(codes)
+		return
+	}
+	if *doProps {
+		outputProps(codes)
+		return
+	}
+	if *doFonts {
+		outputFonts(codes)
+		return
+	}
 	if *doUnic || *doUNIC || *doDesc {
 		desc(codes)
 		return
 	}
+	if *doCols {
+		outputColumns(codes)
+		return
+	}
 	if *doText {
 		fmt.Printf("%s\n", string(codes))
 		return
 	}
-	b := new(bytes.Buffer)
+	w := stdout
+	defer w.Flush()
 	for i, c := range codes {
 		switch {
 		case printRange:
-			fmt.Fprintf(b, "%.4x %c", c, c)
+			fmt.Fprintf(w, "%.4x %c", c, c)
 			if i%4 == 3 {
-				fmt.Fprint(b, "\n")
+				fmt.Fprint(w, "\n")
 			} else {
-				fmt.Fprint(b, "\t")
+				fmt.Fprint(w, "\t")
 			}
 		case *doChar:
-			fmt.Fprintf(b, "%c\n", c)
+			fmt.Fprintf(w, "%c\n", c)
 		case *doNum:
-			fmt.Fprintf(b, "%.4x\n", c)
+			fmt.Fprintf(w, "%.4x\n", c)
 		}
 	}
-	if b.Len() > 0 && b.Bytes()[b.Len()-1] != '\n' {
-		fmt.Fprint(b, "\n")
+	if printRange && len(codes)%4 != 0 {
+		fmt.Fprint(w, "\n")
 	}
-	fmt.Print(b)
 }
 
 func fatalf(format string, args ...interface{}) {
+	stdout.Flush()
+	if finishOutput != nil {
+		finishOutput()
+	}
 	if !strings.HasSuffix(format, "\n") {
 		format += "\n"
 	}
@@ -103,11 +664,130 @@ const usageText = `usage: unicode [-c] [-d] [-n] [-t]
 -c: args are hex; output characters (xyz)
 -n: args are characters; output hex (23 or 23-44)
 -g: args are regular expressions for matching names
+-v re: exclude the characters whose names match re; with -g, or alone or with other filters to list the rest
+-case: match -g and -v against the names as in the database, in upper case with upper-case hex ('\bCJK\b', '^1F6')
+-field f: match -g and -v against field f of UnicodeData.txt instead of the names (code, name, category, ccc, bidi,
+      decomposition, decimal, digit, numeric, mirrored, oldname, comment, upper, lower, title, or 0 to 14)
+-f: args are words of names, in any order and allowing typos; list the best matches
+-N: args are exact names or aliases of characters, ignoring case ('EM DASH')
+-batch file: read queries from file (- for standard input), one to a line: hex codes or ranges (-c), or g:regexp (-g); -d by default
 -d: output textual description
 -t: output plain text, not one char per line
 -U: output full Unicode description
+-lang code: add the CLDR name and keywords in the language (de, pt_BR, ...) to -d and -U, especially of emoji
+-cat: list characters in, or restrict output to, the comma-separated
+      general categories (Sm, L, ...)
+-p: likewise for the comma-separated binary properties (White_Space, Dash, ...)
+-age: likewise for the Unicode version of introduction (15.0, '>=15.0', ...)
+-value: likewise for the numeric values, as numbers, ranges or comparisons (1/2, 1-9, '>=1000', ...)
+-script: likewise for the scripts, by the Script property alone (Arab, Greek, ...)
+-scriptx: likewise for the scripts, by Script_Extensions (Deva, Greek, ...)
+-range, -block: likewise for comma-separated code point ranges (2190-2BFF) or blocks (Cyrillic, 'Latin Extended-A')
+-q: likewise for a query over properties, with | & ! and parentheses ('Script=Greek & gc=Lu & !Age>15.0', 'Dash | lb=HY')
+-set: likewise for an ICU UnicodeSet pattern ('[[:Latin:]&[:Ll:]-[a-m]]', '[\p{Greek}&\P{Ll}]')
+-rand n: choose n of the characters found or listed at random (never surrogates); -seed s makes the choice repeatable
+-sortby key: order the characters found or listed by code (the default), name, category, block or age
+-count: print only the number of characters (and sequences) found or listed
+-byblock: group the characters found or listed under headings naming their blocks
+-cols: print the characters in a grid as wide as the terminal, each above its code point
+-ranges: print the characters found or listed as runs of code points (0410-044f), in the form of -c args
+-gotable name: print the characters found or listed as Go source declaring the *unicode.RangeTable name
+-class dialect: print them as a regexp character class for dialect go, pcre, js or python ([\x{2190}-\x{21FF}])
+-near n: show the n code points before and after each character, described (-d) unless another format is given
+-max n, -skip n: page through what is found or listed, skipping the first n, printing at most n and noting how many remain
+-han: args are regular expressions for matching the meanings and readings
+      of Han characters (horse, ma3, uma)
+-emoji: args are regular expressions for matching the short names and
+      keywords of emoji (face with tears)
+-explain: args are emoji or other sequences; explain their code points
+-emoji-list: list the emoji in CLDR order, as in pickers, by group and subgroup, with their qualification; args, if any, are
+      regular expressions selecting groups or subgroups (flags, 'face-')
+-flag: args are region codes (NL, GB-SCT) or flags; convert one to the other
+-tone n: apply skin tone n, 1 (light) to 5 (dark), to the emoji args; -tone strip or -tone show to remove or report tones
+-vs: args are characters; print their variation sequences (text or emoji style, CJK compatibility forms)
+-rel: args are characters; show the related characters: case pairs, decompositions, cross references, confusables
+-render file: args are characters; draw them with the TrueType font -font f, at -fontsize n pixels (64), as a contact sheet
+      in file, a PNG or SVG image by its suffix; a % verb in the name, as in U+%04X.png, makes one image per character
+-nfc, -nfd, -nfkc, -nfkd: args (or standard input) are text; show the normalized forms
+-decomp: print the recursive canonical and compatibility decomposition of each character
+-props: print every property of each character, enumerated and binary, in the \p{...} notation of -set (\p{gc=Zs}, \p{White_Space})
+-fonts: list the installed fonts, found by fontconfig or in the system font directories, that have a glyph for each character
+-variants: args are characters; list the characters whose decompositions contain them (e: é, ℯ, ᵉ, ...)
+-fold: args (or standard input) are text; show their full case folding
+-upper, -lower, -title: args (or standard input) are text; convert their case and show the mappings applied
+-confuse: args are characters or strings; show their UTS #39 skeletons and the characters confusable with each (needs confusables.txt)
+-spoof: args are strings; report their scripts, UTS #39 restriction level and the characters that mix scripts
+-ident: args are strings; check them as UAX #31 and Go identifiers and name the first invalid character
+-idna: args are domain names; convert them to A-labels (xn--) and U-labels by UTS #46 and name what is disallowed (needs IdnaMappingTable.txt)
+-graphemes: args (or standard input) are text; split them into extended grapheme clusters
+-words, -sentences: args (or standard input) are text; split them into words or sentences, showing the boundary offsets and the break property of each rune
+-linebreak: args (or standard input) are text; mark their UAX #14 line break opportunities
+-width: args (or standard input) are text; compute their display width in a terminal
+-probe: likewise, or without args a set of hard cases; print each to the terminal, ask it where the cursor went, and report where its width differs from -width
+-bidi: args (or standard input) are text; show the UAX #9 bidi class and embedding level of each rune and the display order
+-compose: args are a base character and combining marks; show their combining classes and NFC precomposed form
+-compose-pair: args are two code points (0065 0301) or characters; show the character they compose to canonically, if any, or why not
+-minversion: args are strings (or lines of standard input); show the oldest Unicode version with all their characters and the newest characters, which set it
+-sortkey: args (or standard input) are text; show their UCA collation elements and sort key
+-sort: args (or the lines of standard input) are text; sort them by UCA, or with -locale tag by CLDR's tailoring for that locale
+-ascii: args (or standard input) are text; convert them to ASCII by decomposing, dropping marks and transliterating
+-style name: args (or standard input) are text; write their ASCII in the style (bold, italic, fraktur, double-struck, monospace,
+      fullwidth, circled, small-caps, ...), or with -style plain turn styled text back into ASCII
+-serve addr: serve lookups (/char/1F600) and name searches (/search?q=greek.*alpha) over HTTP as JSON or HTML
+-torture: print test strings for hard cases of text handling (long clusters, mark pileups, bidi controls, noncharacters, invalid UTF-8, joiners, plane edges); -t for raw text
+-blocks, -planes: list the blocks or planes with their ranges and numbers of assigned characters; both together list the blocks of each plane
+-chart block: print an HTML code chart of the block, a grid of glyphs and code points followed by the names (-chart Arrows -o arrows.html)
+-report: args are files (or standard input); summarize their text: sizes, counts by script, category and block, controls and bidi controls
+-suspect: likewise; show where invisible or suspicious characters (zero width, bidi controls, NBSP, soft hyphens, ...) and invalid UTF-8 are
+-newlines: likewise; count the line terminators (LF, CRLF, CR, NEL, VT, FF, LS, PS), flag mixed conventions and show where the unusual ones are
+-scan: args are source files or directories (.); report bidi controls, invisible characters and identifiers that mix scripts, look like ASCII or are not NFC; exit 1 if any
+-strict: exit with status 3 if a character looked up is unassigned, 4 if -g finds nothing, 5 if input is not valid UTF-8
+-list block, -list script:name: describe every assigned character of a block ('Box Drawing') or script (script:Runic)
+-diff: args are two Unicode versions (15.0 16.0); print the characters added, removed, renamed or changed between them, restricted by -block, -range or -cat
+-enc list: print the bytes of each character in the comma-separated legacy encodings (latin1,shiftjis,gb18030), or note it has none
+-o file, -oenc encoding: write the output to a file, or in an encoding such as utf-16le-bom, utf-8-bom or shiftjis
+-copy: also copy the characters found to the clipboard; -paste: take the text on the clipboard as an argument (pbcopy, clip, wl-copy, xclip or xsel)
 
 Default behavior sniffs the arguments to select -c vs. -n.
+
+Flags must come before the arguments: unicode -cat So,Sm -g arrow, not
+unicode -g arrow -cat So,Sm, which takes -cat and So,Sm as patterns.
+
+Hex arguments may be ranges such as 2190-21FF, open at either end
+(2600- runs to the last assigned character, -7F starts at 0; put --
+before an argument that begins with a dash), and comma-separated
+lists such as 0-1F,7F-9F. The same forms are accepted by -range.
+
+Text read from standard input may be in UTF-8, UTF-16 or UTF-32, with or
+without a byte order mark, or failing those in Latin-1. Anything but
+plain UTF-8 is converted, and its encoding reported on standard error.
+
+-d and -U show how to type each character beyond ASCII, where known:
+its sequence for the X11 Compose key, its Vim digraph, and its LaTeX.
+
+Files of the Unicode Character Database too large to embed, such as
+NamesList.txt, are read if present from the directory $UNICODE_UCD,
+by default unicode in the user's cache directory. NamesList.txt adds
+the code chart annotations to -U output, and Unihan_Readings.txt adds
+the meanings and readings of Han characters to -d and -U output.
+The CLDR emoji annotations, annotations/en.xml, add keywords to -emoji.
+Those of other languages, for -lang, are downloaded there on first use.
+confusables.txt, from the security data of UTS #39, is needed by -confuse.
+IdnaMappingTable.txt, of UTS #46, is needed by -idna and adds the IDNA status to -U.
+ucd.all.flat.xml, the database in XML of UAX #42, adds properties such as
+Joining_Type, Indic_Syllabic_Category and Vertical_Orientation to -props,
+-q and -set.
+-update downloads the current release of these files and of the embedded
+ones into the same directory, where they take the place of the embedded
+copies. The embedded UnicodeData.txt may also be replaced by another,
+such as a draft version, or by ucd.all.flat.xml, named by -data or
+$UNICODE_DATA.
+-compile writes a compiled form of the database to the same directory,
+which later runs load without parsing until the database changes.
+-ucd selects an earlier version of Unicode, such as 12.1, whose files
+are downloaded on first use into a subdirectory named for the version.
+Properties taken from Go's unicode package, such as the scripts of
+-script and the binary properties of -p, remain those of Go's tables.
 `
 
 func usage() {
@@ -117,38 +797,42 @@ func usage() {
 // Mode determines whether we have numeric or character input.
 // If there are no flags, we sniff the first argument.
 func mode() {
-	if len(flag.Args()) == 0 {
+	if len(flag.Args()) == 0 && !filtering() && *doBatch == "" {
 		usage()
 	}
-	// If grepping names, we need an output format defined; default is numeric.
-	if *doGrep && !(*doNum || *doChar || *doDesc || *doUnic || *doUNIC) {
+	// If grepping or listing the characters that pass a filter, we need an output format defined; default is numeric.
+	if (searching() || len(flag.Args()) == 0) && !(*doNum || *doChar || *doDesc || *doUnic || *doUNIC) {
 		*doNum = true
 	}
 	if *doNum || *doChar {
 		return
 	}
-	alldigits := true
-	numDash := 0
-	for _, r := range strings.Join(flag.Args(), "") {
-		if !strings.ContainsRune("0123456789abcdefABCDEF-", r) {
-			alldigits = false
-		}
-		if r == '-' {
-			numDash++
+	// Hex numbers and ranges of them, such as 41 or 0-1f,7f-9f, are code points.
+	for _, a := range flag.Args() {
+		if !isCodeRanges(a) {
+			*doNum = true
+			return
 		}
 	}
-	// If there is one '-' it's a range; if zero it's just a hex number.
-	if alldigits && numDash <= 1 {
-		*doChar = true
-		return
-	}
-	*doNum = true
+	*doChar = true
+}
+
+// searching reports whether the arguments are search patterns.
+func searching() bool {
+	return *doGrep || *doFuzzy || *doNames || *doHan || *doVariants || *doEmo
+}
+
+// filtering reports whether a flag restricts the characters to list.
+func filtering() bool {
+	return *doRange != "" || *doBlock != "" || *doList != "" || *doCat != "" || *doProp != "" || *doAge != "" || *doValue != "" || *doScx != "" || *doInvert != "" || *doQuery != "" || *doSet != "" || *doScript != "" || *doRand > 0
 }
 
 func argsAreChars() []rune {
 	var codes []rune
 	for i, a := range flag.Args() {
+		checkUTF8(fmt.Sprintf("argument %q", a), a)
 		for _, r := range a {
+			checkAssigned(r)
 			codes = append(codes, r)
 		}
 		// Add space between arguments if output is plain text.
@@ -159,17 +843,31 @@ func argsAreChars() []rune {
 	return codes
 }
 
+// argsAreNames returns the characters whose names, Unicode 1.0 names or
+// aliases are the arguments, ignoring case.
 func argsAreNames() []rune {
-	var codes []rune
+	want := make(map[string]int)
 	for i, a := range flag.Args() {
-		for _, r := range a {
-			codes = append(codes, r)
+		want[strings.ToUpper(a)] = i
+	}
+	codes := make([]rune, len(flag.Args()))
+	found := make([]bool, len(codes))
+	ucd.Each(func(e ucd.Entry) {
+		for _, n := range searchNames(e) {
+			if i, ok := want[n]; ok && !found[i] {
+				codes[i], found[i] = e.Code, true
+			}
 		}
-		// Add space between arguments if output is plain text.
-		if *doText && i < len(flag.Args())-1 {
-			codes = append(codes, ' ')
+	})
+	for i, a := range flag.Args() {
+		if !found[i] {
+			fatalf("unknown character name %q", a)
 		}
 	}
+	if *doText {
+		// Keep the arguments apart as argsAreChars does.
+		return []rune(strings.Join(strings.Split(string(codes), ""), " "))
+	}
 	return codes
 }
 
@@ -184,19 +882,25 @@ func parseRune(s string) rune {
 func argsAreNumbers() []rune {
 	var codes []rune
 	for _, a := range flag.Args() {
-		if s := strings.Split(a, "-"); len(s) == 2 {
-			printRange = true
-			r1 := parseRune(s[0])
-			r2 := parseRune(s[1])
-			if r2 < r1 {
-				usage()
-			}
-			for ; r1 <= r2; r1++ {
-				codes = append(codes, r1)
-			}
-			continue
+		codes = append(codes, codeRangesOf(a)...)
+	}
+	return codes
+}
+
+// codeRangesOf returns the code points of the hex numbers and ranges of a.
+func codeRangesOf(a string) []rune {
+	var codes []rune
+	if strings.Contains(a, "-") {
+		printRange = true
+	}
+	for _, v := range parseCodeRanges(a) {
+		if v.lo == v.hi {
+			// A range may hold unassigned code points; a single one may not.
+			checkAssigned(v.lo)
+		}
+		for r := v.lo; r <= v.hi; r++ {
+			codes = append(codes, r)
 		}
-		codes = append(codes, parseRune(a))
 	}
 	return codes
 }
@@ -204,25 +908,104 @@ func argsAreNumbers() []rune {
 func argsAreRegexps() []rune {
 	var codes []rune
 	for _, a := range flag.Args() {
-		re, err := regexp.Compile(a)
-		if err != nil {
-			fatalf("%s", err)
-		}
-		for i, line := range unicodeLines {
-			fields := strings.Split(strings.ToLower(line), ";")
-			line = fields[0] + "\t" + fields[1]
-			if fields[10] != "" {
-				line += "; " + fields[10]
-			}
-			if re.MatchString(line) {
-				r, _ := runeOfLine(i, line)
-				codes = append(codes, r)
-			}
+		codes = append(codes, matching(a)...)
+	}
+	return codes
+}
+
+// matching returns the characters whose names match the regular
+// expression, or the field selected by -field.
+func matching(pattern string) []rune {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	var codes []rune
+	if !*doCase && *doField == "" {
+		// The common case, served by the index of names.
+		for _, e := range ucd.Search(re) {
+			codes = append(codes, e.Code)
 		}
+		return codes
 	}
+	ucd.Each(func(e ucd.Entry) {
+		if nameMatch(re, e) {
+			codes = append(codes, e.Code)
+		}
+	})
 	return codes
 }
 
+// nameMatch reports whether re matches the names of e, or the field of
+// its database line selected by -field, in lower case unless -case is set.
+func nameMatch(re *regexp.Regexp, e ucd.Entry) bool {
+	var text string
+	switch {
+	case *doField == "":
+		text = e.SearchText()
+		if text == "" {
+			return false
+		}
+	case !e.Assigned():
+		return false
+	case fieldIndex() == 0:
+		text = fmt.Sprintf("%04X", e.Code)
+	default:
+		text = strings.Split(e.String(), ";")[fieldIndex()-1]
+	}
+	if !*doCase {
+		text = strings.ToLower(text)
+	}
+	return re.MatchString(text)
+}
+
+// fieldNames are the names -field accepts for the fields of a line of
+// UnicodeData.txt, starting with the code point.
+var fieldNames = [...]string{
+	"code",
+	"name",
+	"category",
+	"ccc",
+	"bidi",
+	"decomposition",
+	"decimal",
+	"digit",
+	"numeric",
+	"mirrored",
+	"oldname",
+	"comment",
+	"upper",
+	"lower",
+	"title",
+}
+
+// fieldIndex returns the index of the field named by -field, which may
+// also be given by number.
+func fieldIndex() int {
+	for i, name := range fieldNames {
+		if strings.EqualFold(*doField, name) || *doField == strconv.Itoa(i) {
+			return i
+		}
+	}
+	fatalf("unknown field %q; the fields are %s", *doField, strings.Join(fieldNames[:], ", "))
+	return 0
+}
+
+// notMatching returns the runes of codes whose names do not match expr.
+func notMatching(codes []rune, expr string) []rune {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	var out []rune
+	for _, r := range codes {
+		if !nameMatch(re, ucd.Lookup(r)) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
 func splitLines(text string) []string {
 	lines := strings.Split(text, "\n")
 	// We get an empty final line; drop it.
@@ -232,40 +1015,235 @@ func splitLines(text string) []string {
 	return lines
 }
 
-func runeOfLine(i int, line string) (r rune, tab int) {
-	tab = strings.IndexAny(line, "\t;")
-	if tab < 0 {
-		fatalf("malformed database: line %d", i)
+// A rangeValue holds the fields of a line of a Unicode Character Database
+// property file, such as DerivedAge.txt, which apply to the runes lo through hi.
+type rangeValue struct {
+	lo, hi rune
+	fields []string
+}
+
+// parseRanges parses the lines of a property file, each a code point or
+// range followed by semicolon-separated fields and an optional comment.
+// The result is sorted by code point.
+func parseRanges(text string) []rangeValue {
+	var list []rangeValue
+	for i, line := range splitLines(text) {
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			fatalf("malformed property file: line %d", i+1)
+		}
+		for j := range fields {
+			fields[j] = strings.TrimSpace(fields[j])
+		}
+		var v rangeValue
+		if s := strings.Split(fields[0], ".."); len(s) == 2 {
+			v.lo, v.hi = parseRune(s[0]), parseRune(s[1])
+		} else {
+			v.lo = parseRune(fields[0])
+			v.hi = v.lo
+		}
+		v.fields = fields[1:]
+		list = append(list, v)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].lo < list[j].lo })
+	return list
+}
+
+// lookupRange returns the fields that apply to r in list, or nil.
+func lookupRange(list []rangeValue, r rune) []string {
+	i := sort.Search(len(list), func(i int) bool { return list[i].hi >= r })
+	if i < len(list) && list[i].lo <= r {
+		return list[i].fields
 	}
-	return parseRune(line[0:tab]), tab
+	return nil
 }
 
-func desc(codes []rune) {
-	runeData := make(map[rune]string)
-	for i, l := range unicodeLines {
-		r, tab := runeOfLine(i, l)
-		runeData[r] = l[tab+1:]
+// lookup returns the line of the database for r, minus the code point, or ""
+// if r is unassigned. Runes within ranges are given their own names.
+func lookup(r rune) string {
+	return ucd.Lookup(r).String()
+}
+
+// allRunes returns every rune in the database.
+func allRunes() []rune {
+	var codes []rune
+	ucd.Each(func(e ucd.Entry) {
+		codes = append(codes, e.Code)
+	})
+	return codes
+}
+
+// category returns the general category of r, such as "Lu", or "" if r is not in the database.
+func category(r rune) string {
+	fields := strings.Split(lookup(r), ";")
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// inCategories returns the runes of codes whose general category is in
+// the comma-separated list cats. A one-letter category such as L matches
+// all its subcategories.
+func inCategories(codes []rune, cats string) []rune {
+	list := strings.Split(cats, ",")
+	for _, c := range list {
+		if unicode.Categories[c] == nil {
+			fatalf("unknown category %q", c)
+		}
 	}
+	var out []rune
+	for _, r := range codes {
+		cat := category(r)
+		for _, c := range list {
+			if cat == c || len(c) == 1 && strings.HasPrefix(cat, c) {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func joinAliases(list []ucd.Alias) string {
+	s := make([]string, len(list))
+	for i, a := range list {
+		s[i] = a.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+func desc(codes []rune) {
+	defer stdout.Flush()
 	if *doUNIC {
 		for _, r := range codes {
-			fmt.Printf("%#U %s", r, dumpUnicode(runeData[r]))
+			if ucd.Lookup(r).Assigned() {
+				fmt.Fprintf(stdout, "%#U %s", r, dumpUnicode(lookup(r)))
+			} else {
+				fmt.Fprintf(stdout, "%#U %s\n", r, noEntry(r))
+			}
+			if a := ucd.Aliases(r); len(a) > 0 {
+				fmt.Fprintf(stdout, "\taliases: %s\n", joinAliases(a))
+			}
+			if l := localDescription(string(r)); l != "" {
+				fmt.Fprintf(stdout, "\t%s\n", l)
+			}
+			for _, a := range annotations(r) {
+				fmt.Fprintf(stdout, "\t%s\n", a)
+			}
+			for _, f := range unihanFields {
+				if v := reading(r, f.key); v != "" {
+					fmt.Fprintf(stdout, "\t%s: %s\n", f.desc, v)
+				}
+			}
+			fmt.Fprintf(stdout, "\tscript: %s\n", describeScript(r))
+			fmt.Fprintf(stdout, "\tplane: %s\n", describePlane(r))
+			area, roadmap := describeAllocation(r)
+			fmt.Fprintf(stdout, "\tblock: %s\n", area)
+			if roadmap != "" {
+				fmt.Fprintf(stdout, "\troadmap: %s\n", roadmap)
+			}
+			if m, ok := bidiMirror(r); ok {
+				fmt.Fprintf(stdout, "\tmirrored glyph: %#U\n", m)
+			}
+			fmt.Fprintf(stdout, "\tline break: %s\n", lineBreak(r))
+			fmt.Fprintf(stdout, "\teast asian width: %s\n", eastAsianWidth(r))
+			if v := variations(r); len(v) > 0 {
+				fmt.Fprintf(stdout, "\tvariation sequences: %s\n", joinVariations(v))
+			}
+			for _, c := range specialCasing(r) {
+				fmt.Fprintf(stdout, "\tspecial casing: %s\n", c)
+			}
+			if f := caseFolding(r); len(f) > 0 {
+				fmt.Fprintf(stdout, "\tcase folding: %s\n", joinCaseFolds(f))
+			}
+			if qc := quickCheck(r); len(qc) > 0 {
+				fmt.Fprintf(stdout, "\tquick check: %s\n", strings.Join(qc, ", "))
+			}
+			if cf, ok := nfkcCasefold(r); ok {
+				fmt.Fprintf(stdout, "\tNFKC_Casefold: %s\n", cf)
+			}
+			if i := describeIDNA(r); i != "" {
+				fmt.Fprintf(stdout, "\tIDNA: %s\n", i)
+			}
+			if a := age(r); a != "" {
+				fmt.Fprintf(stdout, "\tage: %s\n", a)
+			}
+			if h := typingHints(r); len(h) > 0 {
+				fmt.Fprintf(stdout, "\tinput: %s\n", strings.Join(h, ", "))
+			}
+			if p := properties(r); len(p) > 0 {
+				fmt.Fprintf(stdout, "\tproperties: %s\n", strings.Join(p, ", "))
+			}
 		}
 	} else if *doUnic {
 		for _, r := range codes {
-			fmt.Printf("%#U %s\n", r, runeData[r])
+			if ucd.Lookup(r).Assigned() {
+				fmt.Fprintf(stdout, "%#U %s\n", r, lookup(r))
+			} else {
+				fmt.Fprintf(stdout, "%#U %s\n", r, noEntry(r))
+			}
 		}
 	} else {
 		for _, r := range codes {
-			fields := strings.Split(strings.ToLower(runeData[r]), ";")
-			desc := fields[0]
+			if !ucd.Lookup(r).Assigned() {
+				fmt.Fprintf(stdout, "%#U %s\n", r, noEntry(r))
+				continue
+			}
+			fields := strings.Split(strings.ToLower(lookup(r)), ";")
+			desc := strings.ToLower(ucd.Name(r))
 			if len(desc) >= 9 && fields[9] != "" {
 				desc += "; " + fields[9]
 			}
-			fmt.Printf("%#U %s\n", r, desc)
+			if def := reading(r, "kDefinition"); def != "" {
+				desc += "; " + def
+				if m := reading(r, "kMandarin"); m != "" {
+					desc += " [" + m + "]"
+				}
+			}
+			if l := localDescription(string(r)); l != "" {
+				desc += "; " + l
+			}
+			if h := typingHints(r); len(h) > 0 {
+				desc += "; " + strings.Join(h, ", ")
+			}
+			if a := age(r); a != "" {
+				desc += " (Unicode " + a + ")"
+			}
+			fmt.Fprintf(stdout, "%#U %s\n", r, desc)
 		}
 	}
 }
 
+// noEntry describes r, which has no entry in the database, as not a code
+// point, a noncharacter, a surrogate, private use or unassigned, followed
+// by the block that holds it, if any.
+func noEntry(r rune) string {
+	var kind string
+	switch {
+	case r < 0 || r > unicode.MaxRune:
+		return "<not a code point>"
+	case unicode.Is(unicode.Noncharacter_Code_Point, r):
+		kind = "<noncharacter>"
+	case unicode.Is(unicode.Cs, r):
+		kind = "<surrogate>"
+	case unicode.Is(unicode.Co, r):
+		kind = "<private use>"
+	default:
+		kind = "<unassigned>"
+	}
+	if b := block(r); b != "No_Block" {
+		kind += " in block " + b
+	}
+	return kind
+}
+
 var prop = [...]string{
 	"",
 	"category: ",
@@ -300,6 +1278,9 @@ func dumpUnicode(s string) []byte {
 		if i > 0 {
 			b.WriteByte('\t')
 		}
+		if i == 2 {
+			f += " " + cccName(f)
+		}
 		fmt.Fprintf(b, "%s%s\n", prop[i], f)
 	}
 	return b.Bytes()
@@ -0,0 +1,128 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// unihanFields lists the fields of Unihan_Readings.txt that are shown
+// for Han characters, with their descriptions.
+var unihanFields = [...]struct {
+	key, desc string
+}{
+	{"kDefinition", "definition"},
+	{"kMandarin", "Mandarin"},
+	{"kCantonese", "Cantonese"},
+	{"kJapaneseOn", "Japanese on"},
+	{"kJapaneseKun", "Japanese kun"},
+}
+
+// unihan maps a rune to its readings, indexed by field key. Unihan_Readings.txt
+// is tens of megabytes so, like NamesList.txt, it is read from ucdDir.
+var unihan map[rune]map[string]string
+
+func loadUnihan() {
+	if unihan != nil {
+		return
+	}
+	unihan = make(map[rune]map[string]string)
+	for _, line := range splitLines(readUCD("Unihan_Readings.txt")) {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		f := strings.SplitN(line, "\t", 3)
+		if len(f) != 3 || !strings.HasPrefix(f[0], "U+") {
+			continue
+		}
+		r := parseRune(f[0][2:])
+		if unihan[r] == nil {
+			unihan[r] = make(map[string]string)
+		}
+		unihan[r][f[1]] = f[2]
+	}
+}
+
+// reading returns the value of the Unihan field key for r, or "".
+func reading(r rune, key string) string {
+	if !unicode.Is(unicode.Ideographic, r) {
+		return ""
+	}
+	loadUnihan()
+	return unihan[r][key]
+}
+
+// argsAreHanRegexps returns the Han characters whose definitions or readings
+// match the argument regexps. Mandarin readings may also be matched in
+// numbered form, such as ma3 for mǎ.
+func argsAreHanRegexps() []rune {
+	loadUnihan()
+	if len(unihan) == 0 {
+		fatalf("no Unihan data: Unihan_Readings.txt not found in %s", ucdDir())
+	}
+	han := make([]rune, 0, len(unihan))
+	for r := range unihan {
+		han = append(han, r)
+	}
+	sort.Slice(han, func(i, j int) bool { return han[i] < han[j] })
+	var codes []rune
+	for _, a := range flag.Args() {
+		re, err := regexp.Compile(a)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		for _, r := range han {
+			var b strings.Builder
+			for _, f := range unihanFields {
+				b.WriteString(unihan[r][f.key])
+				b.WriteString("; ")
+			}
+			b.WriteString(numberedPinyin(unihan[r]["kMandarin"]))
+			if re.MatchString(strings.ToLower(b.String())) {
+				codes = append(codes, r)
+			}
+		}
+	}
+	return codes
+}
+
+// toneMarks maps each pinyin vowel with a tone mark to the vowel and tone number.
+var toneMarks = map[rune]struct {
+	vowel rune
+	tone  byte
+}{
+	'ā': {'a', '1'}, 'á': {'a', '2'}, 'ǎ': {'a', '3'}, 'à': {'a', '4'},
+	'ē': {'e', '1'}, 'é': {'e', '2'}, 'ě': {'e', '3'}, 'è': {'e', '4'},
+	'ī': {'i', '1'}, 'í': {'i', '2'}, 'ǐ': {'i', '3'}, 'ì': {'i', '4'},
+	'ō': {'o', '1'}, 'ó': {'o', '2'}, 'ǒ': {'o', '3'}, 'ò': {'o', '4'},
+	'ū': {'u', '1'}, 'ú': {'u', '2'}, 'ǔ': {'u', '3'}, 'ù': {'u', '4'},
+	'ǖ': {'ü', '1'}, 'ǘ': {'ü', '2'}, 'ǚ': {'ü', '3'}, 'ǜ': {'ü', '4'},
+	'ḿ': {'m', '2'}, 'ń': {'n', '2'}, 'ň': {'n', '3'}, 'ǹ': {'n', '4'},
+}
+
+// numberedPinyin converts space-separated pinyin syllables with tone marks,
+// such as "zhōng", to numbered form, such as "zhong1".
+func numberedPinyin(s string) string {
+	syllables := strings.Fields(s)
+	for i, syl := range syllables {
+		var tone byte
+		syl = strings.Map(func(r rune) rune {
+			if t, ok := toneMarks[r]; ok {
+				tone = t.tone
+				return t.vowel
+			}
+			return r
+		}, syl)
+		if tone != 0 {
+			syl += string(tone)
+		}
+		syllables[i] = syl
+	}
+	return strings.Join(syllables, " ")
+}
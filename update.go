@@ -0,0 +1,195 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// unicodeOrg is the root of the published data files.
+var unicodeOrg = "https://www.unicode.org/Public/"
+
+// updateFiles lists the files fetched by -update: the name under which
+// each is stored in ucdDir and its location relative to unicodeOrg or,
+// if absolute, its URL.
+var updateFiles = []struct{ name, url string }{
+	{"UnicodeData.txt", "UCD/latest/ucd/UnicodeData.txt"},
+	{"NameAliases.txt", "UCD/latest/ucd/NameAliases.txt"},
+	{"NamesList.txt", "UCD/latest/ucd/NamesList.txt"},
+	{"NamedSequences.txt", "UCD/latest/ucd/NamedSequences.txt"},
+	{"Blocks.txt", "UCD/latest/ucd/Blocks.txt"},
+	{"DerivedAge.txt", "UCD/latest/ucd/DerivedAge.txt"},
+	{"PropertyValueAliases.txt", "UCD/latest/ucd/PropertyValueAliases.txt"},
+	{"ScriptExtensions.txt", "UCD/latest/ucd/ScriptExtensions.txt"},
+	{"BidiBrackets.txt", "UCD/latest/ucd/BidiBrackets.txt"},
+	{"BidiMirroring.txt", "UCD/latest/ucd/BidiMirroring.txt"},
+	{"CaseFolding.txt", "UCD/latest/ucd/CaseFolding.txt"},
+	{"SpecialCasing.txt", "UCD/latest/ucd/SpecialCasing.txt"},
+	{"DerivedNormalizationProps.txt", "UCD/latest/ucd/DerivedNormalizationProps.txt"},
+	{"LineBreak.txt", "UCD/latest/ucd/LineBreak.txt"},
+	{"EastAsianWidth.txt", "UCD/latest/ucd/EastAsianWidth.txt"},
+	{"WordBreakProperty.txt", "UCD/latest/ucd/auxiliary/WordBreakProperty.txt"},
+	{"SentenceBreakProperty.txt", "UCD/latest/ucd/auxiliary/SentenceBreakProperty.txt"},
+	{"StandardizedVariants.txt", "UCD/latest/ucd/StandardizedVariants.txt"},
+	{"emoji-data.txt", "UCD/latest/ucd/emoji/emoji-data.txt"},
+	{"emoji-variation-sequences.txt", "UCD/latest/ucd/emoji/emoji-variation-sequences.txt"},
+	{"emoji-test.txt", "emoji/latest/emoji-test.txt"},
+	{"allkeys.txt", "UCA/latest/allkeys.txt"},
+	{"confusables.txt", "security/latest/confusables.txt"},
+	{"IdnaMappingTable.txt", "idna/latest/IdnaMappingTable.txt"},
+	{"Unihan_Readings.txt", "UCD/latest/ucd/Unihan.zip"},
+	{"ucd.all.flat.xml", "UCD/latest/ucdxml/ucd.all.flat.zip"},
+	{"annotations/en.xml", cldrURL + "annotations/en.xml"},
+}
+
+// ucdVersion is the version of Unicode selected by -ucd, such as 12.1.0,
+// or "" for the current one.
+var ucdVersion string
+
+var versionRE = regexp.MustCompile(`^[0-9]+(\.[0-9]+){0,2}$`)
+
+// useVersion selects the version of Unicode, given as 12.1 or 12.1.0, whose
+// files are read from ucdDir, downloading them if they are not there.
+func useVersion(v string) {
+	ucdVersion = fullVersion(v)
+	if readUCD("UnicodeData.txt") == "" && !*doUpdate {
+		update()
+	}
+}
+
+// fullVersion returns the version v, such as 12.1, in full, as 12.1.0.
+func fullVersion(v string) string {
+	if !versionRE.MatchString(v) {
+		fatalf("bad Unicode version %q; want a version such as 12.1", v)
+	}
+	for strings.Count(v, ".") < 2 {
+		v += ".0"
+	}
+	return v
+}
+
+// versionURLs returns the URLs from which to fetch the file at path, a
+// location of the current release relative to unicodeOrg, for ucdVersion.
+// Before Unicode 13.0 the emoji data files were kept with the emoji
+// releases, whose numbers differ up to 11.0.
+func versionURLs(path string) []string {
+	if ucdVersion == "" {
+		return []string{unicodeOrg + path}
+	}
+	var major, minor int
+	fmt.Sscanf(ucdVersion, "%d.%d", &major, &minor)
+	emoji := fmt.Sprintf("%d.%d", major, minor)
+	switch {
+	case major == 10:
+		emoji = "5.0"
+	case major == 9:
+		emoji = "4.0"
+	case major < 9:
+		emoji = "2.0"
+	}
+	var urls []string
+	if strings.HasPrefix(path, "UCD/latest/ucd/emoji/") && major < 13 {
+		urls = append(urls, unicodeOrg+"emoji/"+emoji+"/"+strings.TrimPrefix(path, "UCD/latest/ucd/emoji/"))
+	}
+	path = strings.Replace(path, "UCD/latest/", ucdVersion+"/", 1)
+	path = strings.Replace(path, "emoji/latest/", "emoji/"+emoji+"/", 1)
+	path = strings.Replace(path, "/latest/", "/"+ucdVersion+"/", 1)
+	return append(urls, unicodeOrg+path)
+}
+
+// update downloads the database files of ucdVersion, by default the
+// current release, into ucdDir, where they take the place of the embedded
+// copies. Files missing from an old version are skipped with a warning.
+func update() {
+	dir := ucdDir()
+	if dir == "" {
+		fatalf("-update: no cache directory; set $UNICODE_UCD")
+	}
+	for _, f := range updateFiles {
+		urls := []string{f.url}
+		if !strings.Contains(f.url, "://") {
+			urls = versionURLs(f.url)
+		}
+		var data []byte
+		var err error
+		for _, url := range urls {
+			if data, err = fetch(url); err == nil {
+				break
+			}
+		}
+		if err != nil && ucdVersion != "" {
+			fmt.Fprintf(os.Stderr, "unicode: %s: %s; skipped\n", f.name, err)
+			continue
+		}
+		if err != nil {
+			fatalf("-update: %s", err)
+		}
+		url := urls[len(urls)-1]
+		if filepath.Ext(url) == ".zip" {
+			data, err = unzip(data, f.name)
+			if err != nil {
+				fatalf("-update: %s: %s", url, err)
+			}
+		}
+		path := filepath.Join(dir, filepath.FromSlash(f.name))
+		if err := writeFile(path, data); err != nil {
+			fatalf("-update: %s", err)
+		}
+		fmt.Printf("%s: %d bytes\n", path, len(data))
+	}
+}
+
+// fetch returns the contents of the URL.
+func fetch(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// unzip returns the contents of the named file in the zip archive data.
+func unzip(data []byte, name string) ([]byte, error) {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range z.File {
+		if f.Name == name {
+			r, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return io.ReadAll(r)
+		}
+	}
+	return nil, fmt.Errorf("no %s in archive", name)
+}
+
+// writeFile writes data to path by way of a temporary file, so a failed
+// update leaves no partial file behind.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
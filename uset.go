@@ -0,0 +1,251 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// This file parses the UnicodeSet patterns of ICU, such as
+//
+//	[[:Latin:]&[:Ll:]-[a-m]]
+//
+// A set holds characters, ranges such as a-m, properties written [:Ll:],
+// [:^Ll:], \p{Script=Greek} or \P{Dash}, and nested sets. A leading ^
+// complements a set. Its items are combined left to right: by union,
+// or by & (intersection) or - (difference) with a following set or property.
+// Whitespace is ignored; escapes are \uXXXX, \U00XXXXXX, \x{X...},
+// \N{name} and a backslash before any other character. Multi-character
+// strings such as {ch} are not supported.
+
+// A setParser parses a UnicodeSet pattern.
+type setParser struct {
+	pattern string
+	s       string // The unparsed text.
+}
+
+// parseSet returns the predicate for the UnicodeSet pattern.
+func parseSet(pattern string) predicate {
+	p := &setParser{pattern: pattern, s: pattern}
+	p.skipSpace()
+	pred := p.set()
+	p.skipSpace()
+	if p.s != "" {
+		p.errorf("unexpected %q", p.s)
+	}
+	return pred
+}
+
+func (p *setParser) errorf(format string, args ...interface{}) {
+	fatalf("-set: %s in %q", fmt.Sprintf(format, args...), p.pattern)
+}
+
+func (p *setParser) skipSpace() {
+	p.s = strings.TrimLeftFunc(p.s, unicode.IsSpace)
+}
+
+// peek returns the next non-space character, or 0 at the end.
+func (p *setParser) peek() rune {
+	p.skipSpace()
+	if p.s == "" {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(p.s)
+	return r
+}
+
+// startsSet reports whether a set or property is next.
+func (p *setParser) startsSet() bool {
+	p.skipSpace()
+	return strings.HasPrefix(p.s, "[") || strings.HasPrefix(p.s, `\p`) || strings.HasPrefix(p.s, `\P`)
+}
+
+// set parses a set or property.
+func (p *setParser) set() predicate {
+	switch {
+	case strings.HasPrefix(p.s, "[:"):
+		return p.property(2, ":]")
+	case strings.HasPrefix(p.s, `\p{`), strings.HasPrefix(p.s, `\P{`):
+		negate := p.s[1] == 'P'
+		pred := p.property(3, "}")
+		if negate {
+			return func(r rune) bool { return !pred(r) }
+		}
+		return pred
+	case !strings.HasPrefix(p.s, "["):
+		p.errorf("want [ at %q", p.s)
+	}
+	p.s = p.s[1:]
+	negate := p.peek() == '^'
+	if negate {
+		p.s = p.s[1:]
+	}
+	var pred predicate = func(rune) bool { return false }
+	for {
+		switch c := p.peek(); {
+		case c == 0:
+			p.errorf("missing ]")
+		case c == ']':
+			p.s = p.s[1:]
+			if negate {
+				x := pred
+				return func(r rune) bool { return !x(r) }
+			}
+			return pred
+		case c == '&' || c == '-':
+			p.s = p.s[1:]
+			if !p.startsSet() {
+				p.errorf("%c must be followed by a set", c)
+			}
+			x, y := pred, p.set()
+			if c == '&' {
+				pred = func(r rune) bool { return x(r) && y(r) }
+			} else {
+				pred = func(r rune) bool { return x(r) && !y(r) }
+			}
+		case p.startsSet():
+			x, y := pred, p.set()
+			pred = func(r rune) bool { return x(r) || y(r) }
+		case c == '{':
+			p.errorf("strings such as %q are not supported", p.s[:strings.IndexByte(p.s+"}", '}')+1])
+		default:
+			lo := p.char()
+			hi := lo
+			if p.peek() == '-' && !strings.HasPrefix(strings.TrimLeftFunc(p.s[1:], unicode.IsSpace), "]") {
+				p.s = p.s[1:]
+				p.skipSpace()
+				hi = p.char()
+				if hi < lo {
+					p.errorf("bad range %c-%c", lo, hi)
+				}
+			}
+			x := pred
+			pred = func(r rune) bool { return lo <= r && r <= hi || x(r) }
+		}
+	}
+}
+
+// property parses a property such as [:Ll:] or \p{Script=Greek}, whose
+// text follows a prefix of length start and ends with end.
+func (p *setParser) property(start int, end string) predicate {
+	p.s = p.s[start:]
+	i := strings.Index(p.s, end)
+	if i < 0 {
+		p.errorf("missing %s", end)
+	}
+	text := strings.TrimSpace(p.s[:i])
+	p.s = p.s[i+len(end):]
+	negate := strings.HasPrefix(text, "^")
+	text = strings.TrimPrefix(text, "^")
+	var pred predicate
+	if j := strings.IndexAny(text, "=≠"); j >= 0 {
+		op := "="
+		if strings.HasPrefix(text[j:], "≠") {
+			op = "!="
+		}
+		_, n := utf8.DecodeRuneInString(text[j:])
+		pred = propertyPredicate(strings.TrimSpace(text[:j]), op, strings.TrimSpace(text[j+n:]))
+	} else {
+		pred = namePredicate(text)
+	}
+	if negate {
+		return func(r rune) bool { return !pred(r) }
+	}
+	return pred
+}
+
+// char parses a literal or escaped character.
+func (p *setParser) char() rune {
+	r, n := utf8.DecodeRuneInString(p.s)
+	if r != '\\' {
+		p.s = p.s[n:]
+		return r
+	}
+	p.s = p.s[1:]
+	if p.s == "" {
+		p.errorf("trailing backslash")
+	}
+	var digits string
+	switch p.s[0] {
+	case 'u':
+		digits, p.s = p.take(1, 4)
+	case 'U':
+		digits, p.s = p.take(1, 8)
+	case 'x':
+		if strings.HasPrefix(p.s, "x{") {
+			digits, p.s = p.braced()
+		} else {
+			digits, p.s = p.take(1, 2)
+		}
+	case 'N':
+		var name string
+		name, p.s = p.braced()
+		r := runeNamed(name)
+		if r < 0 {
+			p.errorf("unknown character name %q", name)
+		}
+		return r
+	default:
+		r, n := utf8.DecodeRuneInString(p.s)
+		p.s = p.s[n:]
+		return r
+	}
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || v > unicode.MaxRune {
+		p.errorf("bad escape \\%s", digits)
+	}
+	return rune(v)
+}
+
+// take returns n bytes of p.s after skipping skip, and the rest.
+func (p *setParser) take(skip, n int) (string, string) {
+	if len(p.s) < skip+n {
+		p.errorf("short escape \\%s", p.s)
+	}
+	return p.s[skip : skip+n], p.s[skip+n:]
+}
+
+// braced returns the text within the braces that follow the first byte of
+// p.s, and the rest.
+func (p *setParser) braced() (string, string) {
+	i := strings.IndexByte(p.s, '}')
+	if len(p.s) < 2 || p.s[1] != '{' || i < 0 {
+		p.errorf("bad escape \\%s", p.s)
+	}
+	return p.s[2:i], p.s[i+1:]
+}
+
+// runeNamed returns the character with the name or alias, ignoring case,
+// or -1.
+func runeNamed(name string) rune {
+	name = strings.ToUpper(name)
+	found := rune(-1)
+	ucd.Each(func(e ucd.Entry) {
+		for _, n := range searchNames(e) {
+			if found < 0 && n == name {
+				found = e.Code
+			}
+		}
+	})
+	return found
+}
+
+// inSet returns the runes of codes that are in the UnicodeSet pattern.
+func inSet(codes []rune, pattern string) []rune {
+	pred := parseSet(pattern)
+	var out []rune
+	for _, r := range codes {
+		if pred(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math/big"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+// numericValue returns the Numeric_Value of r, which holds the decimal digit
+// and digit values too, or nil if it has none.
+func numericValue(r rune) *big.Rat {
+	e := ucd.Lookup(r)
+	if e.Numeric == "" {
+		return nil
+	}
+	v, ok := new(big.Rat).SetString(e.Numeric)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// parseValue parses a number such as 12, -1/2 or 0.25.
+func parseValue(s string) *big.Rat {
+	v, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		fatalf("-value: bad number %q", s)
+	}
+	return v
+}
+
+// valuePredicate returns the test of a numeric value for one item of -value:
+// a number, a range lo-hi, or a comparison such as >=1000.
+func valuePredicate(item string) func(v *big.Rat) bool {
+	item = strings.TrimSpace(item)
+	if i := strings.IndexAny(item, "0123456789.-"); i > 0 {
+		cmp := cmpFunc(strings.TrimSpace(item[:i]))
+		if cmp == nil {
+			fatalf("-value: bad comparison %q", item)
+		}
+		n := parseValue(item[i:])
+		return func(v *big.Rat) bool { return cmp(v.Cmp(n)) }
+	}
+	// A minus sign at the start is a negative number, not a range.
+	if i := strings.IndexByte(item[1:], '-'); item != "" && i >= 0 {
+		lo, hi := parseValue(item[:i+1]), parseValue(item[i+2:])
+		if lo.Cmp(hi) > 0 {
+			fatalf("-value: bad range %q", item)
+		}
+		return func(v *big.Rat) bool { return lo.Cmp(v) <= 0 && v.Cmp(hi) <= 0 }
+	}
+	n := parseValue(item)
+	return func(v *big.Rat) bool { return v.Cmp(n) == 0 }
+}
+
+// ofValue returns the runes of codes whose numeric value matches any of the
+// comma-separated numbers, ranges and comparisons of spec, such as 1/2 or 1-9.
+func ofValue(codes []rune, spec string) []rune {
+	var preds []func(*big.Rat) bool
+	for _, item := range strings.Split(spec, ",") {
+		preds = append(preds, valuePredicate(item))
+	}
+	var out []rune
+	for _, r := range codes {
+		v := numericValue(r)
+		if v == nil {
+			continue
+		}
+		for _, pred := range preds {
+			if pred(v) {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"robpike.io/cmd/unicode/ucd"
+)
+
+var (
+	//go:embed StandardizedVariants.txt.gz
+	standardizedVariantsGz []byte
+	//go:embed emoji-variation-sequences.txt.gz
+	emojiVariationsGz []byte
+	variationSeqs     map[rune][]variation
+)
+
+// A variation is a variation sequence: a base character followed by a
+// variation selector, which requests a particular glyph for the base.
+type variation struct {
+	selector rune
+	desc     string // Such as "emoji style" or "CJK COMPATIBILITY IDEOGRAPH-F900".
+}
+
+func (v variation) String() string {
+	return fmt.Sprintf("%04X %s", v.selector, v.desc)
+}
+
+func loadVariations() {
+	if variationSeqs != nil {
+		return
+	}
+	variationSeqs = make(map[rune][]variation)
+	for _, file := range []struct{ name, text string }{
+		{"StandardizedVariants.txt", ucdText("StandardizedVariants.txt", standardizedVariantsGz)},
+		{"emoji-variation-sequences.txt", ucdText("emoji-variation-sequences.txt", emojiVariationsGz)},
+	} {
+		for i, line := range splitLines(file.text) {
+			if j := strings.IndexByte(line, '#'); j >= 0 {
+				line = line[:j]
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			fields := strings.Split(line, ";")
+			seq := strings.Fields(fields[0])
+			if len(fields) < 2 || len(seq) != 2 {
+				fatalf("malformed %s: line %d", file.name, i+1)
+			}
+			base := parseRune(seq[0])
+			variationSeqs[base] = append(variationSeqs[base], variation{parseRune(seq[1]), strings.TrimSpace(fields[1])})
+		}
+	}
+}
+
+// variations returns the variation sequences defined for r.
+func variations(r rune) []variation {
+	loadVariations()
+	return variationSeqs[r]
+}
+
+// argsAreVariations returns the variation sequences of the characters of the arguments.
+func argsAreVariations(args []string) []namedSeq {
+	var seqs []namedSeq
+	for _, a := range args {
+		for _, r := range a {
+			for _, v := range variations(r) {
+				n := ucd.Name(r)
+				seqs = append(seqs, namedSeq{n + "; " + v.desc, string([]rune{r, v.selector})})
+			}
+		}
+	}
+	return seqs
+}
+
+func joinVariations(list []variation) string {
+	s := make([]string, len(list))
+	for i, v := range list {
+		s[i] = v.String()
+	}
+	return strings.Join(s, ", ")
+}
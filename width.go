@@ -0,0 +1,51 @@
+// Copyright 2012 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+var (
+	//go:embed EastAsianWidth.txt.gz
+	eastAsianWidthGz []byte
+	eastAsianWidths  []rangeValue
+)
+
+// eastAsianWidth returns the East_Asian_Width property of r: A (ambiguous),
+// F (fullwidth), H (halfwidth), N (neutral), Na (narrow) or W (wide).
+func eastAsianWidth(r rune) string {
+	if eastAsianWidths == nil {
+		eastAsianWidths = parseRanges(ucdText("EastAsianWidth.txt", eastAsianWidthGz))
+	}
+	if f := lookupRange(eastAsianWidths, r); f != nil {
+		return f[0]
+	}
+	return "N"
+}
+
+// width prints the display width in a terminal of each argument, or each
+// line of standard input if there are none, followed by the width of each
+// of its grapheme clusters and the East_Asian_Width of their runes.
+// The widths are computed by github.com/rivo/uniseg: zero for controls and
+// combining marks, two for wide and fullwidth characters and emoji, one
+// otherwise, including the ambiguous characters.
+func width(args []string) {
+	for _, a := range argsOrStdin(args) {
+		fmt.Printf("'%s'\twidth %d\n", a, uniseg.StringWidth(a))
+		g := uniseg.NewGraphemes(a)
+		for g.Next() {
+			var ea []string
+			for _, r := range g.Runes() {
+				ea = append(ea, eastAsianWidth(r))
+			}
+			fmt.Printf("\t'%s'\t%d\t%s\t%s\n", g.Str(), g.Width(), codePoints(g.Str()), strings.Join(ea, " "))
+		}
+	}
+}